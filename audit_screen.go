@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type AuditMsg struct {
+	findings []auditFinding
+	updated  time.Time
+	warning  error
+	err      error
+}
+
+type AuditFixResponseObject struct {
+	pkg     string
+	version string
+	res     InstallResponseObject
+}
+
+// refreshes the database when it's stale (or always when forced) and audits against whatever is on disk
+func auditPackagesAsync(m *model, force bool) tea.Cmd {
+	var pkgs = m.localPackages
+	var ctx = screenContext(m)
+	return func() tea.Msg {
+		var updated, warning = refreshOSVDatabase(ctx, force)
+		if updated.IsZero() {
+			return AuditMsg{err: warning}
+		}
+		var findings, err = auditPackages(pkgs)
+		return AuditMsg{findings: findings, updated: updated, warning: warning, err: err}
+	}
+}
+
+func importOSVDatabaseAsync(m *model, source string) tea.Cmd {
+	var pkgs = m.localPackages
+	return func() tea.Msg {
+		if err := importOSVDatabase(source); err != nil {
+			return AuditMsg{err: err, updated: osvDatabaseUpdated()}
+		}
+		var findings, err = auditPackages(pkgs)
+		return AuditMsg{findings: findings, updated: osvDatabaseUpdated(), err: err}
+	}
+}
+
+func runAuditFixCommandAndRespondAsync(m *model, pkg string, version string) tea.Cmd {
+	m.info = fmt.Sprintf("%v Upgrading %v to %v...", m.spinner.View(), pkg, version)
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	return tea.Sequence(func() tea.Msg {
+		return AuditFixResponseObject{pkg: pkg, version: version, res: installIntoEnvironment(manager, env, fmt.Sprintf("%v>=%v", pkg, version))}
+	}, fetchPackagesAsync(m))
+}
+
+func startAudit(m *model, force bool) tea.Cmd {
+	m.auditFindings = nil
+	m.auditLoading = true
+	drawAuditTable(m)
+	return auditPackagesAsync(m, force)
+}
+
+func openAuditScreen(m *model) tea.Cmd {
+	m.showAuditScreen = true
+	m.showHomeScreen = false
+	return startAudit(m, false)
+}
+
+func drawAuditTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Package", Width: m.window.width / 6},
+		{Title: "Installed", Width: 10},
+		{Title: "Advisory", Width: m.window.width / 4},
+		{Title: "Severity", Width: 13},
+		{Title: "Affected", Width: m.window.width / 6},
+		{Title: "Fixed In", Width: 10},
+		{Title: "Summary", Width: max(m.window.width-m.window.width/6*2-m.window.width/4-57, 10)},
+	}
+
+	var rows []table.Row
+	for _, finding := range m.auditFindings {
+		var fixed = finding.fixed
+		if fixed == "" {
+			fixed = "none yet"
+		}
+		rows = append(rows, table.Row{finding.pkg, finding.version, finding.cves(), finding.severity, finding.affected, fixed, finding.summary})
+	}
+
+	var cursor = m.auditTable.Cursor()
+	m.auditTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+	if cursor > 0 && cursor < len(rows) {
+		m.auditTable.SetCursor(cursor)
+	}
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.auditTable.SetStyles(s)
+}
+
+func selectedAuditFinding(m *model) (auditFinding, bool) {
+	var cursor = m.auditTable.Cursor()
+	if cursor < 0 || cursor >= len(m.auditFindings) {
+		return auditFinding{}, false
+	}
+	return m.auditFindings[cursor], true
+}
+
+func updateAuditScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		cancelScreenRequests(m)
+		m.showAuditScreen = false
+		m.showHomeScreen = true
+		return nil
+
+	case "U", "enter":
+		var finding, ok = selectedAuditFinding(m)
+		if !ok {
+			return nil
+		}
+		var fixed = fixedVersionFor(m.auditFindings, finding.pkg)
+		if fixed == "" {
+			m.info = fmt.Sprintf("No release of %v fixes every advisory yet", finding.pkg)
+			return nil
+		}
+		openConfirmDialog(m, fmt.Sprintf("Upgrade %v to %v using %v?", finding.pkg, fixed, m.managerInUse), func(m *model) tea.Cmd {
+			return runAuditFixCommandAndRespondAsync(m, finding.pkg, fixed)
+		})
+		return nil
+
+	case "r":
+		return startAudit(m, true)
+
+	case "o":
+		openPromptDialog(m, "Import an OSV advisory zip from", "", func(m *model, value string) tea.Cmd {
+			if value = strings.TrimSpace(value); value == "" {
+				return nil
+			}
+			m.auditFindings = nil
+			m.auditLoading = true
+			drawAuditTable(m)
+			return importOSVDatabaseAsync(m, value)
+		})
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.auditTable, cmd = m.auditTable.Update(msg)
+	return cmd
+}
+
+func drawAuditScreen(m *model) string {
+	var updated = "no advisory database yet"
+	if !m.auditUpdated.IsZero() {
+		updated = "advisories from " + m.auditUpdated.Format("2006-01-02 15:04")
+	}
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Security Audit (%v)", updated))
+
+	var body = m.auditTable.View()
+	switch {
+	case m.auditLoading:
+		body = fmt.Sprintf("%v Checking packages against the OSV database...", m.spinner.View())
+	case m.auditUpdated.IsZero():
+		body = "Connect to download the OSV database or press o to import a zip"
+	case len(m.auditFindings) == 0:
+		body = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("No known vulnerabilities in the installed packages")
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • U/Enter: upgrade to the fixed version • r: re-download advisories • o: import zip • Esc: Home\n" + m.info)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
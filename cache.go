@@ -1,21 +1,74 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+
+	"lazypython/pep"
 )
 
 const cacheFileName = "pypi_packages_cache.json"
-const cacheMaxAge = (24 * 3) * time.Hour
+
+// past this age the list is checked against the index again in the background, with the
+// validators it was served with that costs a 304 when nothing changed
+const cacheRefreshAge = 6 * time.Hour
 
 type PackageCache struct {
-	Packages  []string  `json:"packages"`
+	Indexes []cachedIndex `json:"indexes"`
+	// the last time every index was checked
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// one index's project list and the validators it was served with
+type cachedIndex struct {
+	URL          string   `json:"url"`
+	ETag         string   `json:"etag,omitempty"`
+	LastModified string   `json:"last_modified,omitempty"`
+	Packages     []string `json:"packages"`
+}
+
+// every project on every configured index, a name listed by several indexes shows up once
+func (cache PackageCache) packages() []string {
+	var seen = make(map[string]bool)
+	var packages []string
+	for _, index := range cache.Indexes {
+		for _, name := range index.Packages {
+			if key := pep.Normalize(name); !seen[key] {
+				seen[key] = true
+				packages = append(packages, name)
+			}
+		}
+	}
+	return packages
+}
+
+func (cache PackageCache) stale() bool {
+	return time.Since(cache.Timestamp) > cacheRefreshAge
+}
+
+func (cache PackageCache) index(url string) cachedIndex {
+	for _, index := range cache.Indexes {
+		if index.URL == url {
+			return index
+		}
+	}
+	return cachedIndex{URL: url}
+}
+
+// a private index gets its own list, keyed by the indexes configured
 func getCacheFilePath() (string, error) {
+	if key := indexes.cacheKey(); key != "" {
+		return getCachePath(strings.TrimSuffix(cacheFileName, ".json") + "_" + key + ".json")
+	}
+	return getCachePath(cacheFileName)
+}
+
+// a file in lazypython's cache directory, the directory is created if needed
+func getCachePath(name string) (string, error) {
 	cacheDir, err := os.UserCacheDir()
 	if err != nil {
 		return "", err
@@ -24,57 +77,58 @@ func getCacheFilePath() (string, error) {
 	if err := os.MkdirAll(appCacheDir, 0755); err != nil {
 		return "", err
 	}
-	return filepath.Join(appCacheDir, cacheFileName), nil
+	return filepath.Join(appCacheDir, name), nil
 }
 
-func loadPackagesFromCache() ([]string, bool) {
+// the cached list whatever its age, an old list is still good for searching while it's refreshed
+func loadPackagesFromCache() (PackageCache, bool) {
+	var cache PackageCache
 	cachePath, err := getCacheFilePath()
 	if err != nil {
-		return nil, false
+		return cache, false
 	}
 
 	data, err := os.ReadFile(cachePath)
 	if err != nil {
-		return nil, false
-	}
-
-	var cache PackageCache
-	if err := json.Unmarshal(data, &cache); err != nil {
-		return nil, false
+		return cache, false
 	}
 
-	if time.Since(cache.Timestamp) > cacheMaxAge {
-		return nil, false
+	// caches written before validators were kept have no indexes and are fetched again
+	if err := json.Unmarshal(data, &cache); err != nil || len(cache.Indexes) == 0 {
+		return cache, false
 	}
 
-	return cache.Packages, true
+	return cache, true
 }
 
-func savePackagesToCache(packages []string) error {
+func savePackagesToCache(cache PackageCache) error {
 	cachePath, err := getCacheFilePath()
 	if err != nil {
 		return err
 	}
 
-	cache := PackageCache{
-		Packages:  packages,
-		Timestamp: time.Now(),
-	}
-
 	data, err := json.Marshal(cache)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(cachePath, data, 0644)
+	// written next to the old file and renamed over it, a half written cache is never read
+	var partial = cachePath + ".part"
+	if err := os.WriteFile(partial, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(partial, cachePath)
 }
 
-// that is the question (lmao)
-func toCacheOrNotToCache() bool {
-	if packages, ok := loadPackagesFromCache(); ok {
-		pythonPackages = packages
-		return true
+// asks the indexes whether their lists changed since cache was saved and saves what comes back,
+// the timestamp only moves when every index answered
+func refreshPackageCache(ctx context.Context, client indexClient, cache PackageCache) (PackageCache, bool, error) {
+	var refreshed, changed, err = client.listProjects(ctx, cache)
+	if err == nil {
+		refreshed.Timestamp = time.Now()
 	}
-
-	return false
+	if len(refreshed.packages()) > 0 {
+		savePackagesToCache(refreshed)
+	}
+	return refreshed, changed, err
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"lazypython/pep"
+)
+
+const (
+	compatOk      = "ok"
+	compatBreaks  = "breaks"
+	compatUnknown = "?"
+)
+
+// how one package's Requires-Python lines up with the project's lowest supported python and the active one
+type compatEntry struct {
+	name           string
+	version        string
+	requiresPython string
+	declared       bool
+	fromIndex      bool
+	lowerBound     string
+	active         string
+}
+
+func (e compatEntry) broken() bool {
+	return e.lowerBound == compatBreaks || e.active == compatBreaks
+}
+
+func compatStatus(requiresPython string, version string) string {
+	if version == "" {
+		return compatUnknown
+	}
+	if satisfiesRequiresPython(version, requiresPython) {
+		return compatOk
+	}
+	return compatBreaks
+}
+
+// the oldest python the project claims to support, "" without a lower bound
+func projectLowerBound(projectRange string) string {
+	var set, err = pep.ParseSpecifierSet(projectRange)
+	if err != nil {
+		return ""
+	}
+	if bound, ok := set.LowerBound(); ok {
+		return bound.String()
+	}
+	return ""
+}
+
+// installed packages are checked against their own metadata, declared packages that aren't
+// installed against the latest release on the index
+func checkCompatibility(ctx context.Context, client indexClient, env pythonEnv, pkgs []pythonPackage, projectRange string, activeVersion string) ([]compatEntry, error) {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return nil, err
+	}
+
+	var lower = projectLowerBound(projectRange)
+
+	var declared = make(map[string]bool)
+	for _, pkg := range pkgs {
+		if len(pkg.sources) > 0 {
+			declared[pep.Normalize(pkg.path)] = true
+		}
+	}
+
+	var entries []compatEntry
+	var seen = make(map[string]bool)
+	for _, dist := range readInstalledDistributions(dirs) {
+		var key = pep.Normalize(dist.name)
+		seen[key] = true
+		entries = append(entries, compatEntry{name: dist.name, version: dist.version, requiresPython: dist.requiresPython, declared: declared[key]})
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var limit = make(chan struct{}, 8)
+	for _, pkg := range pkgs {
+		if seen[pep.Normalize(pkg.path)] || len(pkg.sources) == 0 {
+			continue
+		}
+		seen[pep.Normalize(pkg.path)] = true
+
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			var entry = compatEntry{name: name, declared: true, fromIndex: true}
+			if version, requiresPython, err := getRequiresPython(ctx, client, name, ""); err == nil {
+				entry.version, entry.requiresPython = version, requiresPython
+			}
+			mu.Lock()
+			entries = append(entries, entry)
+			mu.Unlock()
+		}(pkg.path)
+	}
+	wg.Wait()
+
+	for i := range entries {
+		entries[i].lowerBound = compatStatus(entries[i].requiresPython, lower)
+		entries[i].active = compatStatus(entries[i].requiresPython, activeVersion)
+		if entries[i].fromIndex && entries[i].version == "" {
+			entries[i].lowerBound, entries[i].active = compatUnknown, compatUnknown
+		}
+	}
+
+	// problems first, declared packages before what they pull in
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].broken() != entries[j].broken() {
+			return entries[i].broken()
+		}
+		if entries[i].declared != entries[j].declared {
+			return entries[i].declared
+		}
+		return pep.Normalize(entries[i].name) < pep.Normalize(entries[j].name)
+	})
+	return entries, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type CompatibilityMsg struct {
+	entries []compatEntry
+	err     error
+}
+
+func checkCompatibilityAsync(m *model) tea.Cmd {
+	var env = m.activeEnv
+	var pkgs = m.localPackages
+	var projectRange = m.requiresPython
+	var active = activePythonVersion(m)
+	var client = m.index
+	var ctx = screenContext(m)
+	return func() tea.Msg {
+		var ctx, cancel = context.WithTimeout(ctx, 2*time.Minute)
+		defer cancel()
+		var entries, err = checkCompatibility(ctx, client, env, pkgs, projectRange, active)
+		return CompatibilityMsg{entries: entries, err: err}
+	}
+}
+
+func openCompatScreen(m *model) tea.Cmd {
+	m.compatEntries = nil
+	m.compatLoading = true
+	m.compatProblemsOnly = false
+	drawCompatTable(m)
+	m.showCompatScreen = true
+	m.showHomeScreen = false
+	return checkCompatibilityAsync(m)
+}
+
+func visibleCompatEntries(m *model) []compatEntry {
+	if !m.compatProblemsOnly {
+		return m.compatEntries
+	}
+	var entries []compatEntry
+	for _, entry := range m.compatEntries {
+		if entry.broken() {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func drawCompatTable(m *model) {
+	var lower = projectLowerBound(m.requiresPython)
+	if lower == "" {
+		lower = "lowest"
+	}
+	var columns = []table.Column{
+		{Title: "Package", Width: m.window.width/4 + 4},
+		{Title: "Version", Width: 12},
+		{Title: "Requires-Python", Width: m.window.width/4 - 4},
+		{Title: "On " + lower, Width: 10},
+		{Title: "On " + activePythonVersion(m), Width: 12},
+		{Title: "Checked", Width: 10},
+	}
+
+	var rows []table.Row
+	for _, entry := range visibleCompatEntries(m) {
+		var name = entry.name
+		if entry.declared {
+			name = "● " + name
+		}
+		var requiresPython = entry.requiresPython
+		if requiresPython == "" {
+			requiresPython = "any"
+		}
+		var checked = "installed"
+		if entry.fromIndex {
+			checked = "latest"
+		}
+		rows = append(rows, table.Row{name, entry.version, requiresPython, entry.lowerBound, entry.active, checked})
+	}
+
+	m.compatTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.compatTable.SetStyles(s)
+}
+
+func updateCompatScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		cancelScreenRequests(m)
+		m.showCompatScreen = false
+		m.showHomeScreen = true
+		return nil
+	case "p":
+		m.compatProblemsOnly = !m.compatProblemsOnly
+		drawCompatTable(m)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.compatTable, cmd = m.compatTable.Update(msg)
+	return cmd
+}
+
+func drawCompatScreen(m *model) string {
+	var projectRange = m.requiresPython
+	if projectRange == "" {
+		projectRange = "not set in pyproject.toml"
+	}
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Python Compatibility (requires-python: %v)", projectRange))
+
+	var body = m.compatTable.View()
+	switch {
+	case m.compatLoading:
+		body = fmt.Sprintf("%v Reading Requires-Python metadata...", m.spinner.View())
+	case len(m.compatEntries) == 0:
+		body = "No packages to check"
+	}
+
+	var broken int
+	for _, entry := range m.compatEntries {
+		if entry.broken() {
+			broken++
+		}
+	}
+	var summary = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("Every package supports the project's range and the active python")
+	if broken > 0 {
+		summary = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("%v package(s) break on the lowest supported python or the active one", broken))
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • p: only problems • ● declared • Esc: Home\n" + summary)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// a small y/n modal, onConfirm only runs when the user accepts
+type confirmDialog struct {
+	prompt    string
+	details   string
+	onConfirm func(m *model) tea.Cmd
+}
+
+func openConfirmDialog(m *model, prompt string, onConfirm func(m *model) tea.Cmd) {
+	m.confirmDialog = &confirmDialog{prompt: prompt, onConfirm: onConfirm}
+}
+
+// the same dialog with what's about to happen spelled out under the question
+func openDetailedConfirmDialog(m *model, prompt string, details string, onConfirm func(m *model) tea.Cmd) {
+	m.confirmDialog = &confirmDialog{prompt: prompt, details: details, onConfirm: onConfirm}
+}
+
+func updateConfirmDialog(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		var dialog = m.confirmDialog
+		m.confirmDialog = nil
+		return dialog.onConfirm(m)
+	case "n", "N", "esc":
+		m.confirmDialog = nil
+	}
+
+	return nil
+}
+
+func drawConfirmScreen(m *model) string {
+	var prompt = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("229")).
+		Render(m.confirmDialog.prompt)
+
+	if m.confirmDialog.details != "" {
+		var details = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("252")).
+			MarginTop(1).
+			Render(m.confirmDialog.details)
+		prompt = lipgloss.JoinVertical(lipgloss.Left, prompt, details)
+	}
+
+	var hint = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Render("y / Enter: confirm • n / Esc: cancel")
+
+	var box = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 3).
+		Render(lipgloss.JoinVertical(lipgloss.Center, prompt, hint))
+
+	return lipgloss.Place(m.window.width, m.window.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"lazypython/pep"
+)
+
+// one declared requirement (or included group) and the list it lives in, empty lists get a
+// row of their own so they can still be picked
+type dependencyRow struct {
+	list        dependencyList
+	requirement string
+	include     string
+}
+
+func loadDependencyRows(m *model) {
+	m.dependencyRows = nil
+	var src, err = readPyproject()
+	if err != nil {
+		return
+	}
+	for _, list := range listDependencyLists(src) {
+		var requirements = dependencyStrings(src, list)
+		var includes = includedGroups(src, list)
+		for _, requirement := range requirements {
+			m.dependencyRows = append(m.dependencyRows, dependencyRow{list: list, requirement: requirement})
+		}
+		for _, include := range includes {
+			m.dependencyRows = append(m.dependencyRows, dependencyRow{list: list, include: include})
+		}
+		if len(requirements) == 0 && len(includes) == 0 {
+			m.dependencyRows = append(m.dependencyRows, dependencyRow{list: list})
+		}
+	}
+}
+
+// the installed version, "missing" when it should be installed here but isn't, "-" when its marker excludes this interpreter
+func dependencyStatus(m *model, requirement string, env pep.Environment) string {
+	var req, err = pep.ParseRequirement(requirement)
+	if err != nil {
+		return "invalid"
+	}
+	for _, pkg := range m.localPackages {
+		if pkg.installed && pep.Normalize(pkg.path) == req.NormalizedName() {
+			return pkg.version
+		}
+	}
+	if !req.Applies(env) {
+		return "-"
+	}
+	return "missing"
+}
+
+func drawDependencyTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Section", Width: 16},
+		{Title: "Requirement", Width: m.window.width - 40},
+		{Title: "Installed", Width: 14},
+	}
+
+	var env = pythonEnvironment(m.activeEnv.interpreter)
+	var rows []table.Row
+	for _, row := range m.dependencyRows {
+		switch {
+		case row.include != "":
+			rows = append(rows, table.Row{row.list.String(), "includes " + row.include, ""})
+		case row.requirement == "":
+			rows = append(rows, table.Row{row.list.String(), "(empty)", ""})
+		default:
+			rows = append(rows, table.Row{row.list.String(), row.requirement, dependencyStatus(m, row.requirement, env)})
+		}
+	}
+
+	var cursor = m.dependencyTable.Cursor()
+	m.dependencyTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+	if cursor > 0 && cursor < len(rows) {
+		m.dependencyTable.SetCursor(cursor)
+	}
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.dependencyTable.SetStyles(s)
+}
+
+func selectedDependencyRow(m *model) (dependencyRow, bool) {
+	var cursor = m.dependencyTable.Cursor()
+	if cursor < 0 || cursor >= len(m.dependencyRows) {
+		return dependencyRow{}, false
+	}
+	return m.dependencyRows[cursor], true
+}
+
+// the selected row when it's an actual requirement, included groups and empty lists aren't
+func selectedDependency(m *model) (dependencyRow, pep.Requirement, bool) {
+	var row, ok = selectedDependencyRow(m)
+	if !ok || row.requirement == "" {
+		return row, pep.Requirement{}, false
+	}
+	var req, err = pep.ParseRequirement(row.requirement)
+	if err != nil {
+		m.info = fmt.Sprintf("err: %v", err.Error())
+		return row, req, false
+	}
+	return row, req, true
+}
+
+func installDependencyListAsync(m *model, list dependencyList) tea.Cmd {
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	m.info = fmt.Sprintf("%v Installing %v...", m.spinner.View(), list)
+	return tea.Sequence(func() tea.Msg {
+		var src, _ = readPyproject()
+		var requirements = resolveDependencyList(src, list, readTomlFile().Project.Name)
+		return GroupInstallResponseObject{list: list, res: manager.sync(env, syncTarget{list: &list, requirements: requirements})}
+	}, fetchPackagesAsync(m))
+}
+
+// edits are quick local file writes so they run right away instead of as a tea.Cmd
+func applyDependencyEdit(m *model, done string, err error) {
+	if err != nil {
+		m.err = err
+		addLog(m, "Error", err.Error())
+		m.info = fmt.Sprintf("err: %v", err.Error())
+		return
+	}
+	addLog(m, "Info", done)
+	m.info = done
+	loadDependencyRows(m)
+	drawDependencyTable(m)
+}
+
+func updateDependencyScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.showDependencyScreen = false
+		m.showHomeScreen = true
+		return fetchPackagesAsync(m)
+
+	case "a":
+		var list = projectDependencies()
+		if row, ok := selectedDependencyRow(m); ok {
+			list = row.list
+		}
+		openPromptDialog(m, fmt.Sprintf("Add a requirement to %v", list), "", func(m *model, value string) tea.Cmd {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				return nil
+			}
+			applyDependencyEdit(m, fmt.Sprintf("Added %v to %v", value, list), addDependency(list, value))
+			return nil
+		})
+		return nil
+
+	case "x":
+		var row, req, ok = selectedDependency(m)
+		if !ok {
+			return nil
+		}
+		openConfirmDialog(m, fmt.Sprintf("Remove %v from %v?", req.Name, row.list), func(m *model) tea.Cmd {
+			applyDependencyEdit(m, fmt.Sprintf("Removed %v from %v", req.Name, row.list), removeDependency(row.list, req.Name))
+			return nil
+		})
+		return nil
+
+	case "c":
+		var row, req, ok = selectedDependency(m)
+		if !ok {
+			return nil
+		}
+		openPromptDialog(m, fmt.Sprintf("Change the constraint on %v", req.Name), row.requirement, func(m *model, value string) tea.Cmd {
+			value = strings.TrimSpace(value)
+			if value == "" || value == row.requirement {
+				return nil
+			}
+			applyDependencyEdit(m, fmt.Sprintf("Changed %v to %v", req.Name, value), changeDependency(row.list, req.Name, value))
+			return nil
+		})
+		return nil
+
+	case "m":
+		var row, req, ok = selectedDependency(m)
+		if !ok {
+			return nil
+		}
+		openPromptDialog(m, fmt.Sprintf("Move %v to (project, a group name or [extra])", req.Name), "", func(m *model, value string) tea.Cmd {
+			if strings.TrimSpace(value) == "" {
+				return nil
+			}
+			var target, err = parseDependencyList(value)
+			if err != nil {
+				m.info = fmt.Sprintf("err: %v", err.Error())
+				return nil
+			}
+			if target == row.list {
+				return nil
+			}
+			applyDependencyEdit(m, fmt.Sprintf("Moved %v from %v to %v", req.Name, row.list, target), moveDependency(row.list, target, req.Name))
+			return nil
+		})
+		return nil
+
+	case "n":
+		openPromptDialog(m, "New dependency group name", "", func(m *model, value string) tea.Cmd {
+			if strings.TrimSpace(value) == "" {
+				return nil
+			}
+			var list, err = parseDependencyList(value)
+			if err == nil && !list.isGroup() {
+				err = fmt.Errorf("%q is not a group name", value)
+			}
+			if err != nil {
+				m.info = fmt.Sprintf("err: %v", err.Error())
+				return nil
+			}
+			applyDependencyEdit(m, fmt.Sprintf("Created group %v", list), createDependencyGroup(list.key))
+			return nil
+		})
+		return nil
+
+	case "i":
+		var row, ok = selectedDependencyRow(m)
+		if !ok {
+			return nil
+		}
+		var list = row.list
+		openConfirmDialog(m, fmt.Sprintf("Install everything in %v using %v?", list, m.managerInUse), func(m *model) tea.Cmd {
+			return installDependencyListAsync(m, list)
+		})
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.dependencyTable, cmd = m.dependencyTable.Update(msg)
+	return cmd
+}
+
+func drawDependencyScreen(m *model) string {
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Dependencies, Extras and Groups (%v)", pyprojectFile))
+
+	var body = m.dependencyTable.View()
+	if len(m.dependencyRows) == 0 {
+		body = fmt.Sprintf("Nothing declared yet, press a to add to [project] dependencies in %v", pyprojectFile)
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • a: add • x: remove • c: change constraint • m: move • n: new group • i: install section • Esc: Home")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"sort"
+
+	"lazypython/pep"
+)
+
+type depNode struct {
+	name       string
+	version    string
+	requires   []string
+	requiredBy []string
+	declared   bool
+	missing    bool
+}
+
+type dependencyGraph struct {
+	nodes map[string]*depNode
+	roots []string
+}
+
+// builds the graph from Requires-Dist, only requirements that apply to this interpreter
+// (and aren't behind an extra) count as edges
+func buildDependencyGraph(dists []distribution, declared []string, env pep.Environment) dependencyGraph {
+	var graph = dependencyGraph{nodes: make(map[string]*depNode)}
+
+	for _, dist := range dists {
+		graph.nodes[pep.Normalize(dist.name)] = &depNode{name: dist.name, version: dist.version}
+	}
+
+	for _, dep := range declared {
+		var req, err = pep.ParseRequirement(dep)
+		if err != nil || !req.Applies(env) {
+			continue
+		}
+		var key = req.NormalizedName()
+		if node, ok := graph.nodes[key]; ok {
+			node.declared = true
+			continue
+		}
+		graph.nodes[key] = &depNode{name: req.Name, declared: true, missing: true}
+	}
+
+	for _, dist := range dists {
+		var parent = pep.Normalize(dist.name)
+		for _, requirement := range dist.requiresDist {
+			var req, err = pep.ParseRequirement(requirement)
+			if err != nil || !req.Applies(env) {
+				continue
+			}
+
+			var key = req.NormalizedName()
+			var child, ok = graph.nodes[key]
+			if !ok {
+				child = &depNode{name: req.Name, missing: true}
+				graph.nodes[key] = child
+			}
+			if containsString(graph.nodes[parent].requires, key) {
+				continue
+			}
+			graph.nodes[parent].requires = append(graph.nodes[parent].requires, key)
+			child.requiredBy = append(child.requiredBy, parent)
+		}
+	}
+
+	var keys []string
+	for key := range graph.nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// declared dependencies first, then everything nothing else pulls in
+	for _, key := range keys {
+		if graph.nodes[key].declared {
+			graph.roots = append(graph.roots, key)
+		}
+	}
+	for _, key := range keys {
+		var node = graph.nodes[key]
+		sort.Strings(node.requires)
+		sort.Strings(node.requiredBy)
+		if !node.declared && len(node.requiredBy) == 0 {
+			graph.roots = append(graph.roots, key)
+		}
+	}
+
+	// packages that only require each other in a cycle would never show up otherwise
+	var reached = make(map[string]bool)
+	var visit func(key string)
+	visit = func(key string) {
+		if reached[key] {
+			return
+		}
+		reached[key] = true
+		for _, child := range graph.nodes[key].requires {
+			visit(child)
+		}
+	}
+	for _, root := range graph.roots {
+		visit(root)
+	}
+	for _, key := range keys {
+		if !reached[key] {
+			graph.roots = append(graph.roots, key)
+			visit(key)
+		}
+	}
+
+	return graph
+}
+
+// installed, not declared and not required by anything
+func (g dependencyGraph) isOrphan(key string) bool {
+	var node = g.nodes[key]
+	return node != nil && !node.declared && !node.missing && len(node.requiredBy) == 0
+}
+
+func loadDependencyGraph(env pythonEnv) (dependencyGraph, error) {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return dependencyGraph{}, err
+	}
+
+	var config = readTomlFile()
+	return buildDependencyGraph(readInstalledDistributions(dirs), config.Project.Dependencies, pythonEnvironment(env.interpreter)), nil
+}
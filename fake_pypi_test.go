@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lazypython/pep"
+)
+
+// a project served by fakePyPI, every version gets one universal wheel
+type fakeProject struct {
+	name           string
+	summary        string
+	requiresPython string
+	versions       []string
+	downloads      downloadStats
+}
+
+func (project fakeProject) filename(version string) string {
+	return fmt.Sprintf("%v-%v-py3-none-any.whl", strings.ReplaceAll(project.name, "-", "_"), version)
+}
+
+func (project fakeProject) sha256(version string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(project.filename(version))))
+}
+
+// an in-process PyPI: the simple index under /simple/, the JSON API under /pypi/ and
+// pypistats under /stats/. A handler set with override replaces what's served for a path
+type fakePyPI struct {
+	*httptest.Server
+	projects map[string]fakeProject
+	// serve the simple pages as PEP 503 HTML instead of PEP 691 JSON
+	html bool
+	etag string
+
+	mu        sync.Mutex
+	requests  map[string][]*http.Request
+	overrides map[string]http.HandlerFunc
+}
+
+func newFakePyPI(t *testing.T, projects ...fakeProject) *fakePyPI {
+	t.Helper()
+	var fake = &fakePyPI{projects: map[string]fakeProject{}, etag: `"1"`, requests: map[string][]*http.Request{}, overrides: map[string]http.HandlerFunc{}}
+	for _, project := range projects {
+		fake.projects[pep.Normalize(project.name)] = project
+	}
+	fake.Server = httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func (fake *fakePyPI) index() packageIndex {
+	return packageIndex{url: fake.URL + "/simple/", from: "test"}
+}
+
+// a client for this index alone, with a short timeout so hanging handlers fail fast
+func (fake *fakePyPI) client() *httpIndexClient {
+	return fakeIndexClient(fake)
+}
+
+func fakeIndexClient(fakes ...*fakePyPI) *httpIndexClient {
+	var cfg indexConfig
+	for _, fake := range fakes {
+		cfg.indexes = append(cfg.indexes, fake.index())
+	}
+	var client = newIndexClient(cfg)
+	client.statsURL = fakes[0].URL + "/stats/"
+	client.http = &http.Client{Timeout: 200 * time.Millisecond}
+	client.backoff = 10 * time.Millisecond
+	return client
+}
+
+func (fake *fakePyPI) override(path string, handler http.HandlerFunc) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.overrides[path] = handler
+}
+
+func (fake *fakePyPI) requestsTo(path string) []*http.Request {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.requests[path]
+}
+
+func (fake *fakePyPI) serve(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	fake.requests[r.URL.Path] = append(fake.requests[r.URL.Path], r)
+	var override = fake.overrides[r.URL.Path]
+	fake.mu.Unlock()
+	if override != nil {
+		override(w, r)
+		return
+	}
+
+	var parts = strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "simple":
+		fake.serveRoot(w, r)
+	case len(parts) == 2 && parts[0] == "simple":
+		fake.serveProjectPage(w, parts[1])
+	case len(parts) == 3 && parts[0] == "pypi" && parts[2] == "json":
+		fake.serveMetadata(w, parts[1], "")
+	case len(parts) == 4 && parts[0] == "pypi" && parts[3] == "json":
+		fake.serveMetadata(w, parts[1], parts[2])
+	case len(parts) == 3 && parts[0] == "stats" && parts[2] == "recent":
+		fake.serveStats(w, parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (fake *fakePyPI) serveRoot(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("If-None-Match") == fake.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", fake.etag)
+
+	if fake.html {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<!DOCTYPE html>\n<html><body>\n")
+		for key, project := range fake.projects {
+			fmt.Fprintf(w, "<a href=\"/simple/%v/\">%v</a><br/>\n", key, html.EscapeString(project.name))
+		}
+		fmt.Fprint(w, "</body></html>")
+		return
+	}
+
+	var root struct {
+		Projects []map[string]string `json:"projects"`
+	}
+	for _, project := range fake.projects {
+		root.Projects = append(root.Projects, map[string]string{"name": project.name})
+	}
+	w.Header().Set("Content-Type", simpleJSONType)
+	json.NewEncoder(w).Encode(root)
+}
+
+func (fake *fakePyPI) serveProjectPage(w http.ResponseWriter, name string) {
+	var project, ok = fake.projects[name]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if fake.html {
+		w.Header().Set("Content-Type", "text/html")
+		for _, version := range project.versions {
+			fmt.Fprintf(w, "<a href=\"../../files/%v#sha256=%v\" data-requires-python=\"%v\">%v</a>\n",
+				project.filename(version), project.sha256(version), html.EscapeString(project.requiresPython), project.filename(version))
+		}
+		return
+	}
+
+	var files []map[string]any
+	for _, version := range project.versions {
+		files = append(files, map[string]any{
+			"filename":        project.filename(version),
+			"url":             "../../files/" + project.filename(version),
+			"hashes":          map[string]string{"sha256": project.sha256(version)},
+			"requires-python": project.requiresPython,
+		})
+	}
+	w.Header().Set("Content-Type", simpleJSONType)
+	json.NewEncoder(w).Encode(map[string]any{"name": name, "files": files})
+}
+
+func (fake *fakePyPI) serveMetadata(w http.ResponseWriter, name string, version string) {
+	var project, ok = fake.projects[pep.Normalize(name)]
+	if !ok || len(project.versions) == 0 {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if version == "" {
+		version = project.versions[len(project.versions)-1]
+	} else if !containsString(project.versions, version) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var releases = map[string][]map[string]any{}
+	for _, v := range project.versions {
+		releases[v] = []map[string]any{{"size": 1000, "upload_time": "2024-01-01T00:00:00"}}
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"info":     map[string]any{"name": project.name, "version": version, "summary": project.summary, "requires_python": project.requiresPython},
+		"releases": releases,
+		"urls": []map[string]any{{
+			"filename": project.filename(version),
+			"url":      fake.URL + "/files/" + project.filename(version),
+			"size":     1000,
+			"digests":  map[string]string{"sha256": project.sha256(version)},
+		}},
+	})
+}
+
+func (fake *fakePyPI) serveStats(w http.ResponseWriter, name string) {
+	var project, ok = fake.projects[pep.Normalize(name)]
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": project.downloads, "package": name})
+}
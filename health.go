@@ -0,0 +1,111 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"lazypython/pep"
+)
+
+const (
+	healthMissing  = "missing"
+	healthConflict = "conflict"
+)
+
+// one requirement an installed package declares that the environment doesn't satisfy
+type healthIssue struct {
+	pkg        string
+	version    string
+	dependency string
+	specifier  string
+	installed  string
+	kind       string
+	// the dependency constrained by everything installed that asks for it, so fixing one
+	// complaint doesn't start another, just this requirement when those ranges don't meet
+	fix string
+}
+
+// what pip check does, read from installed metadata: every requirement of every installed package
+// (extras aside, nothing records which were asked for) has to be installed in a matching version
+func checkEnvironmentHealth(env pythonEnv) ([]healthIssue, error) {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return nil, err
+	}
+
+	var dists = readInstalledDistributions(dirs)
+	var installed = make(map[string]string)
+	for _, dist := range dists {
+		installed[pep.Normalize(dist.name)] = dist.version
+	}
+
+	var markerEnv = pythonEnvironment(env.interpreter)
+	var demands = make(map[string][]pep.Requirement)
+	for _, dist := range dists {
+		for _, line := range dist.requiresDist {
+			if req, err := pep.ParseRequirement(line); err == nil && req.Applies(markerEnv) {
+				demands[dist.name] = append(demands[dist.name], req)
+			}
+		}
+	}
+
+	var specifiers = make(map[string][]string)
+	for _, reqs := range demands {
+		for _, req := range reqs {
+			if spec := req.Specifier.String(); spec != "" && !containsString(specifiers[req.NormalizedName()], spec) {
+				specifiers[req.NormalizedName()] = append(specifiers[req.NormalizedName()], spec)
+			}
+		}
+	}
+
+	var issues []healthIssue
+	for _, dist := range dists {
+		for _, req := range demands[dist.name] {
+			var issue = healthIssue{pkg: dist.name, version: dist.version, dependency: req.Name, specifier: req.Specifier.String()}
+			var version, ok = installed[req.NormalizedName()]
+			switch {
+			case !ok:
+				issue.kind = healthMissing
+			case !req.Specifier.ContainsString(version):
+				issue.kind = healthConflict
+				issue.installed = version
+			default:
+				continue
+			}
+			issue.fix = req.Name + issue.specifier
+			if combined, err := pep.ParseSpecifierSet(strings.Join(specifiers[req.NormalizedName()], ",")); err == nil && satisfiable(combined) {
+				issue.fix = req.Name + combined.String()
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if a, b := pep.Normalize(issues[i].dependency), pep.Normalize(issues[j].dependency); a != b {
+			return a < b
+		}
+		return strings.ToLower(issues[i].pkg) < strings.ToLower(issues[j].pkg)
+	})
+	return issues, nil
+}
+
+// a rough check, packages asking for ranges that don't meet can't all be fixed at once
+func satisfiable(set pep.SpecifierSet) bool {
+	var lower, ok = set.LowerBound()
+	return !ok || set.Contains(lower) || set.ContainsString(lower.String()+".1")
+}
+
+// "install", "upgrade" or "downgrade", which way the fix moves the dependency
+func healthFixAction(issue healthIssue) string {
+	if issue.kind == healthMissing {
+		return "install"
+	}
+	var set, err = pep.ParseSpecifierSet(issue.specifier)
+	if err != nil {
+		return "reinstall"
+	}
+	if lower, ok := set.LowerBound(); ok && compareVersions(lower.String(), issue.installed) > 0 {
+		return "upgrade"
+	}
+	return "downgrade"
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type HealthCheckedMsg struct {
+	issues []healthIssue
+	err    error
+}
+
+type HealthFixResponseObject struct {
+	requirement string
+	res         InstallResponseObject
+}
+
+func checkEnvironmentHealthAsync(m *model) tea.Cmd {
+	var env = m.activeEnv
+	return func() tea.Msg {
+		var issues, err = checkEnvironmentHealth(env)
+		return HealthCheckedMsg{issues: issues, err: err}
+	}
+}
+
+// the environment is checked again once the fix lands, a fix can surface the next problem
+func runHealthFixCommandAndRespondAsync(m *model, requirement string, upgrade bool) tea.Cmd {
+	m.info = fmt.Sprintf("%v Installing %v...", m.spinner.View(), requirement)
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	return tea.Sequence(func() tea.Msg {
+		if upgrade {
+			return HealthFixResponseObject{requirement: requirement, res: manager.upgrade(env, requirement)}
+		}
+		return HealthFixResponseObject{requirement: requirement, res: installIntoEnvironment(manager, env, requirement)}
+	}, fetchPackagesAsync(m), checkEnvironmentHealthAsync(m))
+}
+
+func openHealthScreen(m *model) tea.Cmd {
+	m.healthIssues = nil
+	m.healthLoading = true
+	drawHealthTable(m)
+	m.showHealthScreen = true
+	m.showHomeScreen = false
+	return checkEnvironmentHealthAsync(m)
+}
+
+func drawHealthTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Package", Width: m.window.width / 4},
+		{Title: "Requires", Width: m.window.width / 4},
+		{Title: "Installed", Width: 12},
+		{Title: "Problem", Width: 10},
+		{Title: "Fix", Width: m.window.width/2 - 36},
+	}
+
+	var rows []table.Row
+	for _, issue := range m.healthIssues {
+		var installed = issue.installed
+		if installed == "" {
+			installed = "-"
+		}
+		rows = append(rows, table.Row{
+			fmt.Sprintf("%v %v", issue.pkg, issue.version),
+			issue.dependency + issue.specifier,
+			installed,
+			issue.kind,
+			fmt.Sprintf("%v %v", healthFixAction(issue), issue.fix),
+		})
+	}
+
+	var cursor = m.healthTable.Cursor()
+	m.healthTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+	if cursor > 0 && cursor < len(rows) {
+		m.healthTable.SetCursor(cursor)
+	}
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.healthTable.SetStyles(s)
+}
+
+func selectedHealthIssue(m *model) (healthIssue, bool) {
+	var cursor = m.healthTable.Cursor()
+	if cursor < 0 || cursor >= len(m.healthIssues) {
+		return healthIssue{}, false
+	}
+	return m.healthIssues[cursor], true
+}
+
+func updateHealthScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.showHealthScreen = false
+		m.showHomeScreen = true
+		return nil
+
+	case "r":
+		return openHealthScreen(m)
+
+	case "enter", "i":
+		if issue, ok := selectedHealthIssue(m); ok {
+			var action = healthFixAction(issue)
+			openConfirmDialog(m, fmt.Sprintf("%v%v %v using %v?", strings.ToUpper(action[:1]), action[1:], issue.fix, m.managerInUse), func(m *model) tea.Cmd {
+				return runHealthFixCommandAndRespondAsync(m, issue.fix, false)
+			})
+		}
+		return nil
+
+	// a newer release of the package that asks may well accept what's installed
+	case "U":
+		if issue, ok := selectedHealthIssue(m); ok {
+			openConfirmDialog(m, fmt.Sprintf("Upgrade %v using %v?", issue.pkg, m.managerInUse), func(m *model) tea.Cmd {
+				return runHealthFixCommandAndRespondAsync(m, issue.pkg, true)
+			})
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.healthTable, cmd = m.healthTable.Update(msg)
+	return cmd
+}
+
+func drawHealthScreen(m *model) string {
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Environment Health (%v)", m.activeEnv.name))
+
+	var body = m.healthTable.View()
+	switch {
+	case m.healthLoading:
+		body = fmt.Sprintf("%v Checking installed requirements...", m.spinner.View())
+	case len(m.healthIssues) == 0:
+		body = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("No broken requirements, every installed package has what it needs")
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • Enter/i: apply fix • U: upgrade the package that asks • r: check again • Esc: Home\n" + m.info)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/lipgloss"
+	"lazypython/pep"
 )
 
 func drawHomeScreen(m *model) string {
@@ -75,8 +79,8 @@ func drawHomeScreen(m *model) string {
 		Width(halfWidth).
 		Height(mainHeight).
 		Render(fmt.Sprintf(
-			"Python Version: %v\nInstalled Packages: %v\nPackage Manager: %v",
-			getPythonVersion(), len(m.localPackages), m.managerInUse,
+			"Python Version: %v%v\nEnvironment: %v (%v)\nInstalled Packages: %v\nPackage Manager: %v\nPackage Source: %v\nIndex: %v%v%v",
+			m.pythonVersion, requiresPythonWarning(m), m.activeEnv.name, m.activeEnv.kind, len(m.localPackages), m.managerInUse, m.packageSource, indexStatus(m), lockFileDetails(m), selectedPackageDetails(m),
 		))
 
 	var mainContent = lipgloss.JoinHorizontal(
@@ -107,7 +111,7 @@ func drawHomeScreen(m *model) string {
 		Width(m.window.width - 2).
 		Align(lipgloss.Center).
 		Foreground(lipgloss.Color("240")).
-		Render("j k Navigate | Tab Switch | Ctrl+C Quit")
+		Render("j k Navigate | Tab Switch | x Uninstall | s Mark | U Upgrade | A Upgrade all | m Source | v Envs | t Tree | T Tools | i Pythons | c Compat | a Audit | l Licenses | h Health | D Deps | S Sync | L Lock | e Export | R Refresh index | Ctrl+C Quit")
 
 	var footer = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
@@ -126,3 +130,77 @@ func drawHomeScreen(m *model) string {
 
 	return screen
 }
+
+// flags an active python the project's requires-python doesn't allow
+func requiresPythonWarning(m *model) string {
+	if _, err := pep.ParseVersion(activePythonVersion(m)); err != nil || satisfiesRequiresPython(activePythonVersion(m), m.requiresPython) {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf(" (outside requires-python %v)", m.requiresPython))
+}
+
+func lockFileDetails(m *model) string {
+	if !m.lockFile.found() {
+		return ""
+	}
+	var drifted = driftedPackages(m.localPackages, m.lockFile)
+	if len(drifted) == 0 {
+		return fmt.Sprintf("\nLockfile: %v (in sync)", m.lockFile.kind)
+	}
+	var count = len(drifted)
+	if count > 5 {
+		drifted = append(drifted[:5], "...")
+	}
+	return fmt.Sprintf("\nLockfile: %v (%v drifted: %v)", m.lockFile.kind, count, strings.Join(drifted, ", "))
+}
+
+// only site-packages metadata carries these, pip freeze leaves them empty
+func selectedPackageDetails(m *model) string {
+	var pkg, ok = selectedLocalPackage(m)
+	if !ok || !m.focusOnLocalPackageTable || (pkg.summary == "" && pkg.installer == "") {
+		return ""
+	}
+
+	var details = fmt.Sprintf("\n\n%v %v\n%v\nInstaller: %v", pkg.path, pkg.version, pkg.summary, pkg.installer)
+	if pkg.editable {
+		details += " (editable)"
+	}
+	if pkg.license != "" {
+		details += fmt.Sprintf("\nLicense: %v", pkg.license)
+	}
+	// optional extras would drown out what the package actually needs
+	var requires []string
+	for _, r := range pkg.requires {
+		if req, err := pep.ParseRequirement(r); err == nil && (req.Marker == nil || !strings.Contains(req.Marker.String(), "extra")) {
+			requires = append(requires, req.Name)
+		}
+	}
+	if len(requires) > 0 {
+		details += fmt.Sprintf("\nRequires: %v", strings.Join(requires, ", "))
+	}
+	return details
+}
+
+// the configured index and how old the package list searched on the install screen is
+func indexStatus(m *model) string {
+	var status = indexes.String()
+	switch {
+	case m.indexRefreshing:
+		status += fmt.Sprintf(" (%v refreshing...)", m.spinner.View())
+	case !m.indexUpdated.IsZero():
+		status += fmt.Sprintf(" (updated %v)", formatAge(time.Since(m.indexUpdated)))
+	}
+	return status
+}
+
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%vm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%vh ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%vd ago", int(age.Hours()/24))
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+const defaultIndexURL = "https://pypi.org/simple/"
+
+// a simple API root packages are looked up and installed from
+type packageIndex struct {
+	url      string
+	username string
+	password string
+	// where the index was configured, shown on the home screen
+	from string
+	// credentials written in the URL or .lazypython.toml have to be handed to install commands,
+	// ones from netrc or the keyring are found there by pip and uv themselves
+	inline bool
+}
+
+// the first index is the default, the rest are extra indexes searched in order
+type indexConfig struct {
+	indexes []packageIndex
+}
+
+var indexes = indexConfig{indexes: []packageIndex{{url: defaultIndexURL, from: "default"}}}
+
+func (cfg indexConfig) primary() packageIndex {
+	return cfg.indexes[0]
+}
+
+func (cfg indexConfig) isDefault() bool {
+	return len(cfg.indexes) == 1 && cfg.indexes[0].url == defaultIndexURL && cfg.indexes[0].username == ""
+}
+
+// lazypython's own settings win, then the environment, then [tool.uv], then pip.conf. Every source
+// can add extra indexes, only the first default index found is used
+func loadIndexConfig() (indexConfig, error) {
+	var primary *packageIndex
+	var extras []packageIndex
+	var add = func(index packageIndex, isDefault bool) {
+		if index.url == "" {
+			return
+		}
+		index = splitIndexCredentials(index)
+		if isDefault {
+			if primary == nil {
+				primary = &index
+			}
+			return
+		}
+		for _, extra := range extras {
+			if extra.url == index.url {
+				return
+			}
+		}
+		extras = append(extras, index)
+	}
+
+	var settings, err = readSettings()
+	for _, setting := range settings.Indexes {
+		add(packageIndex{url: setting.URL, username: os.ExpandEnv(setting.Username), password: os.ExpandEnv(setting.Password), from: settingsFile, inline: true}, setting.Default)
+	}
+
+	for _, key := range []string{"PIP_INDEX_URL", "UV_DEFAULT_INDEX", "UV_INDEX_URL"} {
+		add(packageIndex{url: os.Getenv(key), from: "$" + key}, true)
+	}
+	for _, key := range []string{"PIP_EXTRA_INDEX_URL", "UV_INDEX", "UV_EXTRA_INDEX_URL"} {
+		for _, value := range strings.Fields(os.Getenv(key)) {
+			add(packageIndex{url: value, from: "$" + key}, false)
+		}
+	}
+
+	var uv = readUvSettings()
+	for _, index := range uv.Index {
+		// explicit indexes only serve the packages pinned to them in [tool.uv.sources]
+		if !index.Explicit {
+			add(packageIndex{url: index.URL, from: "[tool.uv]"}, index.Default)
+		}
+	}
+	add(packageIndex{url: uv.IndexURL, from: "[tool.uv]"}, true)
+	for _, extra := range uv.ExtraIndexURL {
+		add(packageIndex{url: extra, from: "[tool.uv]"}, false)
+	}
+
+	for _, path := range pipConfigFiles() {
+		var values = readPipConfig(path)
+		add(packageIndex{url: values["index-url"], from: path}, true)
+		for _, extra := range strings.Fields(values["extra-index-url"]) {
+			add(packageIndex{url: extra, from: path}, false)
+		}
+	}
+
+	var cfg indexConfig
+	if primary == nil {
+		primary = &packageIndex{url: defaultIndexURL, from: "default"}
+	}
+	cfg.indexes = append([]packageIndex{*primary}, extras...)
+	for i := range cfg.indexes {
+		cfg.indexes[i] = findIndexCredentials(cfg.indexes[i])
+	}
+	return cfg, err
+}
+
+// uv.toml holds the same keys at the top level that pyproject.toml keeps under [tool.uv]
+func readUvSettings() uvSettings {
+	if data, err := os.ReadFile("uv.toml"); err == nil {
+		var settings uvSettings
+		toml.Unmarshal(data, &settings)
+		return settings
+	}
+	return readTomlFile().Tool.Uv
+}
+
+// pip's own lookup order, most specific first: $PIP_CONFIG_FILE, the virtualenv, the user, the system
+func pipConfigFiles() []string {
+	var name = "pip.conf"
+	if runtime.GOOS == "windows" {
+		name = "pip.ini"
+	}
+
+	var candidates []string
+	if path := os.Getenv("PIP_CONFIG_FILE"); path != "" {
+		candidates = append(candidates, path)
+	}
+	if venv := os.Getenv("VIRTUAL_ENV"); venv != "" {
+		candidates = append(candidates, filepath.Join(venv, name))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		candidates = append(candidates, filepath.Join(dir, "pip", name))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ".pip", name))
+	}
+	if runtime.GOOS != "windows" {
+		candidates = append(candidates, filepath.Join("/etc", "xdg", "pip", name), filepath.Join("/etc", name))
+	}
+
+	var files []string
+	for _, path := range candidates {
+		if fileExists(path) && !containsString(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// the index settings of a pip config file, [install] overrides [global]. pip accepts
+// index_url as well as index-url, and indented lines continue the previous value
+func readPipConfig(path string) map[string]string {
+	var sections = map[string]map[string]string{"global": {}, "install": {}}
+	var file, err = os.Open(path)
+	if err != nil {
+		return sections["global"]
+	}
+	defer file.Close()
+
+	var section, last string
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line = scanner.Text()
+		var trimmed = strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";"):
+			continue
+		case strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]"):
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			last = ""
+			continue
+		case (line[0] == ' ' || line[0] == '\t') && last != "" && sections[section] != nil:
+			sections[section][last] += " " + trimmed
+			continue
+		}
+
+		var key, value, found = strings.Cut(trimmed, "=")
+		if !found || sections[section] == nil {
+			last = ""
+			continue
+		}
+		last = strings.ReplaceAll(strings.TrimSpace(key), "_", "-")
+		sections[section][last] = strings.TrimSpace(value)
+	}
+
+	var values = sections["global"]
+	for key, value := range sections["install"] {
+		values[key] = value
+	}
+	return values
+}
+
+// user:password@ in the URL is moved into the index, the URL keeps a trailing slash
+func splitIndexCredentials(index packageIndex) packageIndex {
+	var parsed, err = url.Parse(strings.TrimSpace(index.url))
+	if err != nil {
+		return index
+	}
+	if parsed.User != nil {
+		index.username = parsed.User.Username()
+		index.password, _ = parsed.User.Password()
+		index.inline = true
+		parsed.User = nil
+	}
+	if !strings.HasSuffix(parsed.Path, "/") {
+		parsed.Path += "/"
+	}
+	index.url = parsed.String()
+	return index
+}
+
+// ~/.netrc first, then the keyring CLI, which needs a username to look the password up by
+func findIndexCredentials(index packageIndex) packageIndex {
+	if index.password != "" {
+		return index
+	}
+	var parsed, err = url.Parse(index.url)
+	if err != nil {
+		return index
+	}
+
+	if login, password, ok := netrcCredentials(parsed.Hostname()); ok && (index.username == "" || index.username == login) {
+		index.username, index.password = login, password
+		return index
+	}
+
+	if index.username != "" && onPath("keyring") {
+		if output, err := exec.Command("keyring", "get", index.url, index.username).Output(); err == nil {
+			index.password = strings.TrimSpace(string(output))
+		} else if output, err := exec.Command("keyring", "get", parsed.Hostname(), index.username).Output(); err == nil {
+			index.password = strings.TrimSpace(string(output))
+		}
+	}
+	return index
+}
+
+// login and password for host from $NETRC or ~/.netrc, a default entry matches any host
+func netrcCredentials(host string) (string, string, bool) {
+	var path = os.Getenv("NETRC")
+	if path == "" {
+		var home, err = os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		path = filepath.Join(home, ".netrc")
+		if runtime.GOOS == "windows" && !fileExists(path) {
+			path = filepath.Join(home, "_netrc")
+		}
+	}
+	var data, err = os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+
+	var fields = strings.Fields(string(data))
+	var login, password string
+	var matched, found bool
+	for i := 0; i < len(fields); i++ {
+		var next = func() string {
+			if i+1 < len(fields) {
+				i++
+				return fields[i]
+			}
+			return ""
+		}
+		switch fields[i] {
+		case "machine":
+			if matched {
+				return login, password, true
+			}
+			matched = next() == host
+		case "default":
+			if matched {
+				return login, password, true
+			}
+			matched = true
+		case "login":
+			if value := next(); matched {
+				login, found = value, true
+			}
+		case "password":
+			if value := next(); matched {
+				password, found = value, true
+			}
+		case "account":
+			next()
+		}
+	}
+	return login, password, matched && found
+}
+
+// the URL with its credentials written back in, how pip and uv take them on the command line
+func (index packageIndex) authenticatedURL() string {
+	if index.username == "" {
+		return index.url
+	}
+	var parsed, err = url.Parse(index.url)
+	if err != nil {
+		return index.url
+	}
+	if index.password != "" {
+		parsed.User = url.UserPassword(index.username, index.password)
+	} else {
+		parsed.User = url.User(index.username)
+	}
+	return parsed.String()
+}
+
+func (index packageIndex) commandURL() string {
+	if index.inline {
+		return index.authenticatedURL()
+	}
+	return index.url
+}
+
+// PyPI and most registries that mirror it serve the JSON API next to the simple one
+func (index packageIndex) jsonURL(path string) string {
+	var base = strings.TrimSuffix(strings.TrimSuffix(index.url, "/"), "/simple")
+	return base + "/pypi/" + path
+}
+
+func (index packageIndex) host() string {
+	if parsed, err := url.Parse(index.url); err == nil {
+		return parsed.Host
+	}
+	return index.url
+}
+
+// a GET with the index's credentials, sent as basic auth
+func (index packageIndex) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if index.username != "" {
+		req.SetBasicAuth(index.username, index.password)
+	}
+	return req, nil
+}
+
+func (index packageIndex) get(ctx context.Context, client *httpIndexClient, url string) (*http.Response, error) {
+	var req, err = index.newRequest(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return client.do(req)
+}
+
+// the environment install commands run with so pip, uv, pdm and hatch use the same indexes lazypython
+// reads, nothing is added when only PyPI is configured
+func (cfg indexConfig) commandEnv() []string {
+	if cfg.isDefault() {
+		return nil
+	}
+	var extras []string
+	for _, index := range cfg.indexes[1:] {
+		extras = append(extras, index.commandURL())
+	}
+	var primary = cfg.primary().commandURL()
+	var env = []string{
+		"PIP_INDEX_URL=" + primary,
+		"UV_INDEX_URL=" + primary,
+		"PDM_PYPI_URL=" + primary,
+	}
+	if len(extras) > 0 {
+		env = append(env,
+			"PIP_EXTRA_INDEX_URL="+strings.Join(extras, " "),
+			"UV_EXTRA_INDEX_URL="+strings.Join(extras, " "),
+		)
+	}
+	return env
+}
+
+// a command with the configured indexes added to its environment
+func withIndexEnv(cmd *exec.Cmd) *exec.Cmd {
+	var env = indexes.commandEnv()
+	if len(env) == 0 {
+		return cmd
+	}
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, env...)
+	return cmd
+}
+
+// a short description for the home screen, "pypi.org" unless something else is configured
+func (cfg indexConfig) String() string {
+	var description = cfg.primary().host()
+	if len(cfg.indexes) > 1 {
+		description += fmt.Sprintf(" (+%v extra)", len(cfg.indexes)-1)
+	}
+	return description
+}
+
+// each set of indexes gets its own package list cache, pypi keeps the original file name
+func (cfg indexConfig) cacheKey() string {
+	if cfg.isDefault() {
+		return ""
+	}
+	var hash = fnv.New32a()
+	for _, index := range cfg.indexes {
+		hash.Write([]byte(index.url + "\n"))
+	}
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const pypiStatsURL = "https://pypistats.org/api/packages/"
+
+// how long an index gets to start answering, reading the body can take as long as the caller's
+// context allows since the full project list is tens of megabytes
+const indexHeaderTimeout = 30 * time.Second
+
+// everything lazypython asks package indexes for. The model holds one so screens never reach for
+// the network themselves, tests hand it a client pointed at a fake index. Failures are *indexError
+type indexClient interface {
+	// the project names of every index, asked conditionally with the validators in cache. An index
+	// that can't be reached keeps its cached list, changed reports whether any index sent a new one
+	listProjects(ctx context.Context, cache PackageCache) (refreshed PackageCache, changed bool, err error)
+	// the JSON API document of a project, of one release when version isn't empty
+	projectMetadata(ctx context.Context, name string, version string) (PackageInfo, error)
+	// the files on a project's simple page
+	projectFiles(ctx context.Context, name string) ([]projectFile, error)
+	downloadStats(ctx context.Context, name string) (downloadStats, error)
+}
+
+// talks to the configured indexes over HTTP, and to pypistats.org for downloads
+type httpIndexClient struct {
+	indexes  indexConfig
+	statsURL string
+	http     *http.Client
+	// a request that times out, can't connect or gets a 429 / 5xx is sent up to attempts times,
+	// waiting backoff before the second and twice as long before each one after
+	attempts int
+	backoff  time.Duration
+}
+
+func newIndexClient(cfg indexConfig) *httpIndexClient {
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = indexHeaderTimeout
+	return &httpIndexClient{
+		indexes:  cfg,
+		statsURL: pypiStatsURL,
+		http:     &http.Client{Transport: transport},
+		attempts: 3,
+		backoff:  500 * time.Millisecond,
+	}
+}
+
+// why a request to an index failed
+const (
+	indexNotFound     = "not found"
+	indexUnauthorized = "unauthorized"
+	indexUnavailable  = "unavailable"
+	indexTimeout      = "timed out"
+	indexOffline      = "unreachable"
+	indexMalformed    = "malformed response"
+	indexCanceled     = "canceled"
+)
+
+type indexError struct {
+	url  string
+	kind string
+	// the HTTP status when the index answered
+	status string
+	err    error
+}
+
+func (e *indexError) Error() string {
+	var detail = e.status
+	if detail == "" && e.err != nil {
+		detail = e.err.Error()
+	}
+	switch e.kind {
+	case indexNotFound:
+		return fmt.Sprintf("%v: not found on the index", e.url)
+	case indexUnauthorized:
+		return fmt.Sprintf("%v: %v, check the index credentials", e.url, detail)
+	case indexUnavailable:
+		return fmt.Sprintf("%v: %v, the index is having trouble, try again later", e.url, detail)
+	case indexTimeout:
+		return fmt.Sprintf("%v: timed out", e.url)
+	case indexOffline:
+		return fmt.Sprintf("%v: can't reach the index, check the connection (%v)", e.url, detail)
+	case indexMalformed:
+		return fmt.Sprintf("%v: the index sent something unreadable (%v)", e.url, detail)
+	}
+	return fmt.Sprintf("%v: %v", e.url, e.kind)
+}
+
+func (e *indexError) Unwrap() error {
+	return e.err
+}
+
+func isIndexError(err error, kind string) bool {
+	var indexErr *indexError
+	return errors.As(err, &indexErr) && indexErr.kind == kind
+}
+
+// a failed request, err is what http.Client.Do returned
+func requestError(rawURL string, err error) *indexError {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	var kind = indexOffline
+	switch {
+	case errors.Is(err, context.Canceled):
+		kind = indexCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		kind = indexTimeout
+	}
+	return &indexError{url: rawURL, kind: kind, err: err}
+}
+
+// an answer other than the one asked for
+func statusError(rawURL string, resp *http.Response) *indexError {
+	var kind = indexUnavailable
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		kind = indexNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		kind = indexUnauthorized
+	}
+	return &indexError{url: rawURL, kind: kind, status: resp.Status}
+}
+
+func malformedError(rawURL string, err error) *indexError {
+	return &indexError{url: rawURL, kind: indexMalformed, err: err}
+}
+
+// sends req, again after a pause when the index is unreachable, slow or overloaded. Any answer
+// below 500 other than a 429 is returned for the caller to judge, as is the last failure
+func (client *httpIndexClient) do(req *http.Request) (*http.Response, error) {
+	var ctx = req.Context()
+	var rawURL = req.URL.Redacted()
+	var delay = client.backoff
+	for attempt := 1; ; attempt++ {
+		var resp, err = client.http.Do(req)
+		var failure *indexError
+		switch {
+		case err != nil:
+			failure = requestError(rawURL, err)
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			failure = statusError(rawURL, resp)
+			if wait := retryAfter(resp); wait > delay {
+				delay = wait
+			}
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		var retry = failure.kind == indexUnavailable || failure.kind == indexOffline || failure.kind == indexTimeout
+		if !retry || attempt >= client.attempts || ctx.Err() != nil {
+			return nil, failure
+		}
+		select {
+		case <-ctx.Done():
+			return nil, requestError(rawURL, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// the wait a 429 or 503 asked for in seconds, HTTP dates aren't worth the trouble
+func retryAfter(resp *http.Response) time.Duration {
+	var seconds, err = strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return min(time.Duration(seconds)*time.Second, 30*time.Second)
+}
+
+func (client *httpIndexClient) listProjects(ctx context.Context, cache PackageCache) (PackageCache, bool, error) {
+	var refreshed = PackageCache{Timestamp: cache.Timestamp}
+	var changed bool
+	var errs []error
+	for _, index := range client.indexes.indexes {
+		var cached = cache.index(index.url)
+		var listing, err = index.listProjects(ctx, client, cached)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if listing.ETag != cached.ETag || listing.LastModified != cached.LastModified || len(listing.Packages) != len(cached.Packages) {
+			changed = true
+		}
+		refreshed.Indexes = append(refreshed.Indexes, listing)
+	}
+	return refreshed, changed, errors.Join(errs...)
+}
+
+func (client *httpIndexClient) projectMetadata(ctx context.Context, name string, version string) (PackageInfo, error) {
+	var pkg PackageInfo
+	var path = fmt.Sprintf("%v/json", url.PathEscape(name))
+	if version != "" {
+		path = fmt.Sprintf("%v/%v/json", url.PathEscape(name), url.PathEscape(version))
+	}
+	return pkg, client.getJSON(ctx, path, &pkg)
+}
+
+// the first index that has the project
+func (client *httpIndexClient) projectFiles(ctx context.Context, name string) ([]projectFile, error) {
+	var lastErr error
+	for _, index := range client.indexes.indexes {
+		var files, err = index.projectFiles(ctx, client, name)
+		if err == nil {
+			return files, nil
+		}
+		if isIndexError(err, indexCanceled) {
+			return nil, err
+		}
+		lastErr = preferredError(lastErr, err)
+	}
+	return nil, lastErr
+}
+
+func (client *httpIndexClient) downloadStats(ctx context.Context, name string) (downloadStats, error) {
+	var stats struct {
+		Data downloadStats `json:"data"`
+	}
+	var statsURL = client.statsURL + url.PathEscape(name) + "/recent"
+	var req, err = http.NewRequestWithContext(ctx, http.MethodGet, statsURL, nil)
+	if err != nil {
+		return stats.Data, err
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return stats.Data, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return stats.Data, statusError(statsURL, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return stats.Data, malformedError(statsURL, err)
+	}
+	return stats.Data, nil
+}
+
+// decodes the JSON API response for path from the first index that has it
+func (client *httpIndexClient) getJSON(ctx context.Context, path string, v any) error {
+	var lastErr error
+	for _, index := range client.indexes.indexes {
+		var url = index.jsonURL(path)
+		var resp, err = index.get(ctx, client, url)
+		if err != nil {
+			if isIndexError(err, indexCanceled) {
+				return err
+			}
+			lastErr = preferredError(lastErr, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = preferredError(lastErr, statusError(url, resp))
+			continue
+		}
+		err = json.NewDecoder(resp.Body).Decode(v)
+		resp.Body.Close()
+		if err != nil {
+			return malformedError(url, err)
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// when every index fails, a project missing from an extra index says less than the default one being down
+func preferredError(previous error, err error) error {
+	if previous == nil || isIndexError(previous, indexNotFound) {
+		return err
+	}
+	return previous
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var requestsProject = fakeProject{
+	name:           "requests",
+	summary:        "Python HTTP for Humans.",
+	requiresPython: ">=3.8",
+	versions:       []string{"2.31.0", "2.32.3"},
+	downloads:      downloadStats{LastDay: 10, LastWeek: 70, LastMonth: 300},
+}
+
+var zopeProject = fakeProject{name: "zope.interface", versions: []string{"6.4"}}
+
+func sortedPackages(cache PackageCache) []string {
+	var packages = cache.packages()
+	slices.Sort(packages)
+	return packages
+}
+
+func TestListProjects(t *testing.T) {
+	for _, format := range []string{"json", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var fake = newFakePyPI(t, requestsProject, zopeProject)
+			fake.html = format == "html"
+
+			var cache, changed, err = fake.client().listProjects(context.Background(), PackageCache{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !changed {
+				t.Error("first listing should report a change")
+			}
+			if got, want := sortedPackages(cache), []string{"requests", "zope.interface"}; !slices.Equal(got, want) {
+				t.Errorf("packages = %v, want %v", got, want)
+			}
+			if cache.Indexes[0].ETag != fake.etag {
+				t.Errorf("etag = %q, want %q", cache.Indexes[0].ETag, fake.etag)
+			}
+			if accept := fake.requestsTo("/simple/")[0].Header.Get("Accept"); !strings.HasPrefix(accept, simpleJSONType) {
+				t.Errorf("Accept = %q, want JSON first", accept)
+			}
+		})
+	}
+}
+
+func TestListProjectsNotModified(t *testing.T) {
+	var fake = newFakePyPI(t, requestsProject)
+	var client = fake.client()
+	var cache, _, err = client.listProjects(context.Background(), PackageCache{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, changed, err := client.listProjects(context.Background(), cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed {
+		t.Error("a 304 should not report a change")
+	}
+	if got := sortedPackages(refreshed); !slices.Equal(got, []string{"requests"}) {
+		t.Errorf("packages = %v, want the cached list", got)
+	}
+	if match := fake.requestsTo("/simple/")[1].Header.Get("If-None-Match"); match != fake.etag {
+		t.Errorf("If-None-Match = %q, want %q", match, fake.etag)
+	}
+
+	fake.etag = `"2"`
+	fake.projects["zope-interface"] = zopeProject
+	refreshed, changed, err = client.listProjects(context.Background(), refreshed)
+	if err != nil || !changed {
+		t.Fatalf("changed = %v, err = %v, want a new list", changed, err)
+	}
+	if got := sortedPackages(refreshed); !slices.Equal(got, []string{"requests", "zope.interface"}) {
+		t.Errorf("packages = %v", got)
+	}
+}
+
+// an index that fails keeps serving the list it sent last time
+func TestListProjectsErrorsKeepCache(t *testing.T) {
+	var cases = map[string]http.HandlerFunc{
+		"server error": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		},
+		"malformed json": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", simpleJSONType)
+			fmt.Fprint(w, `{"projects": [{"name": `)
+		},
+		"timeout": func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		},
+	}
+	for name, handler := range cases {
+		t.Run(name, func(t *testing.T) {
+			var fake = newFakePyPI(t, requestsProject)
+			var client = fake.client()
+			var cache, _, err = client.listProjects(context.Background(), PackageCache{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fake.override("/simple/", handler)
+			refreshed, changed, err := client.listProjects(context.Background(), PackageCache{Indexes: []cachedIndex{{URL: fake.index().url, Packages: cache.Indexes[0].Packages}}})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if changed {
+				t.Error("a failed refresh should not report a change")
+			}
+			if got := sortedPackages(refreshed); !slices.Equal(got, []string{"requests"}) {
+				t.Errorf("packages = %v, want the cached list", got)
+			}
+		})
+	}
+}
+
+func TestListProjectsMergesIndexes(t *testing.T) {
+	var primary = newFakePyPI(t, requestsProject)
+	var extra = newFakePyPI(t, requestsProject, zopeProject)
+
+	var cache, _, err = fakeIndexClient(primary, extra).listProjects(context.Background(), PackageCache{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sortedPackages(cache), []string{"requests", "zope.interface"}; !slices.Equal(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+}
+
+func TestGetPackageInfo(t *testing.T) {
+	var fake = newFakePyPI(t, requestsProject)
+
+	var pkg, err = getPackageInfo(context.Background(), fake.client(), "requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Info.Name != "requests" || pkg.Info.Version != "2.32.3" || pkg.Info.Summary != requestsProject.summary {
+		t.Errorf("info = %+v", pkg.Info)
+	}
+	if len(pkg.Releases) != 2 {
+		t.Errorf("releases = %v, want 2", len(pkg.Releases))
+	}
+	if pkg.Downloads != requestsProject.downloads {
+		t.Errorf("downloads = %+v, want %+v", pkg.Downloads, requestsProject.downloads)
+	}
+}
+
+// the project is still shown when pypistats is down, just without downloads
+func TestGetPackageInfoWithoutStats(t *testing.T) {
+	var fake = newFakePyPI(t, requestsProject)
+	fake.override("/stats/requests/recent", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	})
+
+	var pkg, err = getPackageInfo(context.Background(), fake.client(), "requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Info.Version != "2.32.3" || pkg.Downloads != (downloadStats{}) {
+		t.Errorf("pkg = %+v, want the info without downloads", pkg)
+	}
+}
+
+func TestGetPackageInfoErrors(t *testing.T) {
+	var cases = []struct {
+		name     string
+		handler  http.HandlerFunc
+		kind     string
+		attempts int
+	}{
+		{"not found", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "not found", http.StatusNotFound)
+		}, indexNotFound, 1},
+		{"unauthorized", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "who are you", http.StatusUnauthorized)
+		}, indexUnauthorized, 1},
+		{"server error", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}, indexUnavailable, 3},
+		{"malformed json", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"info": {"name": "requests", `)
+		}, indexMalformed, 1},
+		{"wrong shape", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"info": "requests"}`)
+		}, indexMalformed, 1},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}, indexTimeout, 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var fake = newFakePyPI(t, requestsProject)
+			fake.override("/pypi/requests/json", c.handler)
+
+			var _, err = getPackageInfo(context.Background(), fake.client(), "requests")
+			var indexErr *indexError
+			if !errors.As(err, &indexErr) || indexErr.kind != c.kind {
+				t.Fatalf("err = %v, want a %q indexError", err, c.kind)
+			}
+			if got := len(fake.requestsTo("/pypi/requests/json")); got != c.attempts {
+				t.Errorf("requests = %v, want %v", got, c.attempts)
+			}
+			if len(fake.requestsTo("/stats/requests/recent")) != 0 {
+				t.Error("downloads were asked for a project that failed to load")
+			}
+		})
+	}
+}
+
+// an overloaded index gets a few more tries before the lookup fails
+func TestGetPackageInfoRetries(t *testing.T) {
+	var fake = newFakePyPI(t, requestsProject)
+	var failures = 2
+	fake.override("/pypi/requests/json", func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		fake.serveMetadata(w, "requests", "")
+	})
+
+	var pkg, err = getPackageInfo(context.Background(), fake.client(), "requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Info.Version != "2.32.3" {
+		t.Errorf("version = %q, want 2.32.3", pkg.Info.Version)
+	}
+	if got := len(fake.requestsTo("/pypi/requests/json")); got != 3 {
+		t.Errorf("requests = %v, want 3", got)
+	}
+}
+
+// leaving the screen cancels the lookup without waiting out the retries
+func TestGetPackageInfoCanceled(t *testing.T) {
+	var fake = newFakePyPI(t, requestsProject)
+	var started = make(chan struct{}, 1)
+	fake.override("/pypi/requests/json", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-r.Context().Done()
+	})
+	var client = fake.client()
+	client.http.Timeout = 0
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	var _, err = getPackageInfo(ctx, client, "requests")
+	if !isIndexError(err, indexCanceled) {
+		t.Fatalf("err = %v, want canceled", err)
+	}
+	if got := len(fake.requestsTo("/pypi/requests/json")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+}
+
+// a project missing from the default index is looked up on the extra ones
+func TestGetPackageInfoExtraIndex(t *testing.T) {
+	var primary = newFakePyPI(t, requestsProject)
+	var extra = newFakePyPI(t, zopeProject)
+
+	var pkg, err = getPackageInfo(context.Background(), fakeIndexClient(primary, extra), "zope.interface")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Info.Version != "6.4" {
+		t.Errorf("version = %q, want 6.4", pkg.Info.Version)
+	}
+}
+
+// registries without the JSON API still have their files listed on the simple page
+func TestReleaseHashesFromSimpleAPI(t *testing.T) {
+	for _, format := range []string{"json", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var fake = newFakePyPI(t, requestsProject)
+			fake.html = format == "html"
+			fake.override("/pypi/requests/2.31.0/json", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "not found", http.StatusNotFound)
+			})
+
+			var hashes, err = getReleaseHashes(context.Background(), fake.client(), "requests", "2.31.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"sha256:" + requestsProject.sha256("2.31.0")}; !slices.Equal(hashes, want) {
+				t.Errorf("hashes = %v, want %v", hashes, want)
+			}
+		})
+	}
+}
+
+func TestProjectFiles(t *testing.T) {
+	for _, format := range []string{"json", "html"} {
+		t.Run(format, func(t *testing.T) {
+			var fake = newFakePyPI(t, requestsProject)
+			fake.html = format == "html"
+
+			var files, err = fake.client().projectFiles(context.Background(), "Requests")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != 2 {
+				t.Fatalf("files = %+v, want 2", files)
+			}
+			var file = files[0]
+			if file.version("requests") != "2.31.0" || file.requiresPython != ">=3.8" || file.hashes["sha256"] != requestsProject.sha256("2.31.0") {
+				t.Errorf("file = %+v", file)
+			}
+			if want := fake.URL + "/files/" + requestsProject.filename("2.31.0"); file.url != want {
+				t.Errorf("url = %q, want %q", file.url, want)
+			}
+		})
+	}
+}
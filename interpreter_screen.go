@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type InterpretersLoadedMsg struct {
+	interpreters []pythonInterpreter
+	err          error
+}
+
+type InterpreterInstalledMsg struct {
+	version string
+	res     InstallResponseObject
+}
+
+func loadInterpretersAsync() tea.Cmd {
+	return func() tea.Msg {
+		var interpreters, err = listInterpreters()
+		return InterpretersLoadedMsg{interpreters: interpreters, err: err}
+	}
+}
+
+func installInterpreterAsync(m *model, interpreter pythonInterpreter) tea.Cmd {
+	m.info = fmt.Sprintf("%v Installing Python %v with %v...", m.spinner.View(), interpreter.version, interpreter.via)
+	return tea.Sequence(func() tea.Msg {
+		return InterpreterInstalledMsg{version: interpreter.version, res: runInterpreterInstallCommandAndRespond(interpreter)}
+	}, loadInterpretersAsync())
+}
+
+// "Python 3.12.1" -> "3.12.1"
+func activePythonVersion(m *model) string {
+	return strings.TrimSpace(strings.TrimPrefix(m.pythonVersion, "Python"))
+}
+
+func openInterpreterScreen(m *model) tea.Cmd {
+	m.interpreters = nil
+	m.interpretersLoading = true
+	drawInterpreterTable(m)
+	m.showInterpreterScreen = true
+	m.showHomeScreen = false
+	return loadInterpretersAsync()
+}
+
+func drawInterpreterTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Version", Width: 10},
+		{Title: "Implementation", Width: 14},
+		{Title: "Via", Width: 6},
+		{Title: "Status", Width: 10},
+		{Title: "Project", Width: 8},
+		{Title: "Path", Width: m.window.width - 68},
+	}
+
+	var pinned = readPythonVersionFile()
+	var rows []table.Row
+	for _, interpreter := range m.interpreters {
+		var status = "available"
+		if interpreter.installed {
+			status = "installed"
+		}
+		var fits = "ok"
+		if !satisfiesRequiresPython(interpreter.version, m.requiresPython) {
+			fits = "no"
+		}
+		var version = interpreter.version
+		if pinned != "" && (version == pinned || strings.HasPrefix(version, pinned+".")) {
+			version = "● " + version
+		}
+		rows = append(rows, table.Row{version, interpreter.implementation, interpreter.via, status, fits, interpreter.path})
+	}
+
+	var cursor = m.interpreterTable.Cursor()
+	m.interpreterTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+	if cursor > 0 && cursor < len(rows) {
+		m.interpreterTable.SetCursor(cursor)
+	}
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.interpreterTable.SetStyles(s)
+}
+
+func selectedInterpreter(m *model) (pythonInterpreter, bool) {
+	var cursor = m.interpreterTable.Cursor()
+	if cursor < 0 || cursor >= len(m.interpreters) {
+		return pythonInterpreter{}, false
+	}
+	return m.interpreters[cursor], true
+}
+
+func updateInterpreterScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.showInterpreterScreen = false
+		m.showHomeScreen = true
+		return nil
+
+	case "enter":
+		var interpreter, ok = selectedInterpreter(m)
+		if !ok {
+			return nil
+		}
+		if interpreter.installed {
+			m.info = fmt.Sprintf("Python %v is already installed", interpreter.version)
+			return nil
+		}
+		openConfirmDialog(m, fmt.Sprintf("Install Python %v with %v?", interpreter.version, interpreter.via), func(m *model) tea.Cmd {
+			return installInterpreterAsync(m, interpreter)
+		})
+		return nil
+
+	case "w":
+		var interpreter, ok = selectedInterpreter(m)
+		if !ok {
+			return nil
+		}
+		openPromptDialog(m, fmt.Sprintf("Pin the project to (written to %v)", pythonVersionFile), interpreter.version, func(m *model, value string) tea.Cmd {
+			if value = strings.TrimSpace(value); value == "" {
+				return nil
+			}
+			if err := writePythonVersionFile(value); err != nil {
+				m.err = err
+				addLog(m, "Error", err.Error())
+				m.info = fmt.Sprintf("err: %v", err.Error())
+				return nil
+			}
+			addLog(m, "Info", fmt.Sprintf("Pinned Python %v in %v", value, pythonVersionFile))
+			m.info = fmt.Sprintf("Pinned Python %v", value)
+			drawInterpreterTable(m)
+			return nil
+		})
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.interpreterTable, cmd = m.interpreterTable.Update(msg)
+	return cmd
+}
+
+func drawInterpreterScreen(m *model) string {
+	var title = fmt.Sprintf("Python Interpreters (active: %v", activePythonVersion(m))
+	if pinned := readPythonVersionFile(); pinned != "" {
+		title += fmt.Sprintf(", pinned: %v", pinned)
+	}
+	if m.requiresPython != "" {
+		title += fmt.Sprintf(", requires-python: %v", m.requiresPython)
+	}
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(title + ")")
+
+	var body = m.interpreterTable.View()
+	switch {
+	case m.interpretersLoading:
+		body = fmt.Sprintf("%v Asking uv and pyenv for interpreters...", m.spinner.View())
+	case len(m.interpreters) == 0:
+		body = "No interpreters found, install uv or pyenv to manage them from here"
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • Enter: install • w: write .python-version • Esc: Home\n" + m.info)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
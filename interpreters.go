@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"lazypython/pep"
+)
+
+const pythonVersionFile = ".python-version"
+
+// an interpreter uv or pyenv has installed, or could install
+type pythonInterpreter struct {
+	version        string
+	implementation string
+	path           string
+	via            string
+	installed      bool
+}
+
+var pyenvReleasePattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// everything uv and pyenv know about, newest first, a backend that isn't installed is skipped
+func listInterpreters() ([]pythonInterpreter, error) {
+	var interpreters []pythonInterpreter
+	var errs []string
+
+	if onPath("uv") {
+		var output, err = exec.Command("uv", "python", "list").Output()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("uv: %v", err.Error()))
+		}
+		interpreters = append(interpreters, parseUvPythonList(string(output))...)
+	}
+
+	if onPath("pyenv") {
+		var installed, err = exec.Command("pyenv", "versions", "--bare").Output()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("pyenv: %v", err.Error()))
+		}
+		available, _ := exec.Command("pyenv", "install", "--list").Output()
+		root, _ := exec.Command("pyenv", "root").Output()
+		interpreters = append(interpreters, parsePyenvVersions(string(installed), string(available), strings.TrimSpace(string(root)))...)
+	}
+
+	if !onPath("uv") && !onPath("pyenv") {
+		return nil, fmt.Errorf("neither uv nor pyenv is installed")
+	}
+
+	sort.SliceStable(interpreters, func(i, j int) bool {
+		return compareVersions(interpreters[i].version, interpreters[j].version) > 0
+	})
+	if len(errs) > 0 {
+		return interpreters, fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return interpreters, nil
+}
+
+// "cpython-3.12.3-linux-x86_64-gnu    /usr/bin/python3.12" or "...    <download available>"
+func parseUvPythonList(output string) []pythonInterpreter {
+	var interpreters []pythonInterpreter
+	for _, line := range strings.Split(output, "\n") {
+		var fields = strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		var key = strings.Split(fields[0], "-")
+		if len(key) < 2 {
+			continue
+		}
+		var location = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+		var interpreter = pythonInterpreter{version: key[1], implementation: key[0], via: "uv"}
+		if location != "<download available>" {
+			interpreter.installed = true
+			interpreter.path, _, _ = strings.Cut(location, " -> ")
+		}
+		interpreters = append(interpreters, interpreter)
+	}
+	return interpreters
+}
+
+// pyenv lists every build it knows, only the newest CPython release of each minor version is offered
+func parsePyenvVersions(installed string, available string, root string) []pythonInterpreter {
+	var interpreters []pythonInterpreter
+	var have = make(map[string]bool)
+	for _, version := range strings.Fields(installed) {
+		// pyenv-virtualenv environments show up here too
+		if _, err := pep.ParseVersion(version); err != nil {
+			continue
+		}
+		have[version] = true
+		var interpreter = pythonInterpreter{version: version, implementation: "cpython", via: "pyenv", installed: true}
+		if root != "" {
+			interpreter.path = filepath.Join(root, "versions", version, "bin", "python")
+		}
+		interpreters = append(interpreters, interpreter)
+	}
+
+	var newest = make(map[string]string)
+	for _, version := range strings.Fields(available) {
+		if !pyenvReleasePattern.MatchString(version) || !strings.HasPrefix(version, "3.") {
+			continue
+		}
+		var parts = strings.Split(version, ".")
+		var minor = parts[0] + "." + parts[1]
+		if current, ok := newest[minor]; !ok || compareVersions(version, current) > 0 {
+			newest[minor] = version
+		}
+	}
+	for _, version := range newest {
+		if !have[version] {
+			interpreters = append(interpreters, pythonInterpreter{version: version, implementation: "cpython", via: "pyenv"})
+		}
+	}
+	return interpreters
+}
+
+func runInterpreterInstallCommandAndRespond(interpreter pythonInterpreter) InstallResponseObject {
+	if interpreter.via == "pyenv" {
+		return runCommandAndRespond(exec.Command("pyenv", "install", "--skip-existing", interpreter.version))
+	}
+	return runCommandAndRespond(exec.Command("uv", "python", "install", interpreter.version))
+}
+
+// uv, pyenv and most editors read .python-version from the project root
+func writePythonVersionFile(version string) error {
+	return os.WriteFile(pythonVersionFile, []byte(version+"\n"), 0644)
+}
+
+func readPythonVersionFile() string {
+	var data, err = os.ReadFile(pythonVersionFile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// false when the version is outside requires-python, an unset or unparseable range fits anything
+func satisfiesRequiresPython(version string, requiresPython string) bool {
+	if strings.TrimSpace(requiresPython) == "" {
+		return true
+	}
+	var set, err = pep.ParseSpecifierSet(requiresPython)
+	if err != nil {
+		return true
+	}
+	return set.ContainsString(version)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type LicensesCheckedMsg struct {
+	entries []licenseEntry
+	err     error
+}
+
+type LicensesExportedMsg struct {
+	path string
+	err  error
+}
+
+// the policy is read again every time so edits to .lazypython.toml show up without a restart
+func checkLicensesAsync(m *model) tea.Cmd {
+	var env = m.activeEnv
+	return func() tea.Msg {
+		var settings, err = readSettings()
+		if err != nil {
+			return LicensesCheckedMsg{err: err}
+		}
+		var entries, checkErr = checkLicenses(env, settings.Licenses)
+		return LicensesCheckedMsg{entries: entries, err: checkErr}
+	}
+}
+
+func exportLicensesAsync(m *model, path string) tea.Cmd {
+	var entries = m.licenseEntries
+	m.info = fmt.Sprintf("%v Exporting to %v...", m.spinner.View(), path)
+	return func() tea.Msg {
+		return LicensesExportedMsg{path: path, err: exportLicenses(path, entries)}
+	}
+}
+
+func openLicenseScreen(m *model) tea.Cmd {
+	m.licenseEntries = nil
+	m.licensesLoading = true
+	m.licenseViolationsOnly = false
+	drawLicenseTable(m)
+	m.showLicenseScreen = true
+	m.showHomeScreen = false
+	return checkLicensesAsync(m)
+}
+
+func visibleLicenseEntries(m *model) []licenseEntry {
+	if !m.licenseViolationsOnly {
+		return m.licenseEntries
+	}
+	var entries []licenseEntry
+	for _, entry := range m.licenseEntries {
+		if entry.violation() {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func drawLicenseTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Package", Width: m.window.width / 4},
+		{Title: "Version", Width: 12},
+		{Title: "License", Width: m.window.width/2 - 20},
+		{Title: "From", Width: 18},
+		{Title: "Policy", Width: 12},
+	}
+
+	var rows []table.Row
+	for _, entry := range visibleLicenseEntries(m) {
+		var license = entry.license
+		if license == "" {
+			license = "?"
+		}
+		// a styled cell would break the selected row highlight, violations are marked with !
+		var status = entry.status
+		if entry.violation() {
+			status = "! " + status
+		}
+		rows = append(rows, table.Row{entry.name, entry.version, license, entry.from, status})
+	}
+
+	m.licenseTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.licenseTable.SetStyles(s)
+}
+
+func updateLicenseScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.showLicenseScreen = false
+		m.showHomeScreen = true
+		return nil
+
+	case "v":
+		m.licenseViolationsOnly = !m.licenseViolationsOnly
+		drawLicenseTable(m)
+		return nil
+
+	case "r":
+		return openLicenseScreen(m)
+
+	case "e":
+		if len(m.licenseEntries) == 0 {
+			return nil
+		}
+		openPromptDialog(m, "Export the license report to (.csv or .json)", "licenses.csv", func(m *model, value string) tea.Cmd {
+			if value = strings.TrimSpace(value); value == "" {
+				return nil
+			}
+			return exportLicensesAsync(m, value)
+		})
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.licenseTable, cmd = m.licenseTable.Update(msg)
+	return cmd
+}
+
+func drawLicenseScreen(m *model) string {
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Licenses (policy: %v)", settingsFile))
+
+	var body = m.licenseTable.View()
+	switch {
+	case m.licensesLoading:
+		body = fmt.Sprintf("%v Reading license metadata...", m.spinner.View())
+	case len(m.licenseEntries) == 0:
+		body = "No installed packages to check"
+	}
+
+	var violations, unknown int
+	for _, entry := range m.licenseEntries {
+		if entry.violation() {
+			violations++
+		}
+		if entry.status == licenseUnknown {
+			unknown++
+		}
+	}
+	var summary = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("Every package is within the license policy")
+	if violations > 0 {
+		summary = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(fmt.Sprintf("%v package(s) violate the license policy", violations))
+	}
+	if unknown > 0 {
+		summary += lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render(fmt.Sprintf(", %v declare no license", unknown))
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • v: only violations • e: export CSV/JSON • r: reload policy • Esc: Home\n" + summary + "\n" + m.info)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
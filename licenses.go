@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"lazypython/pep"
+)
+
+const (
+	licenseAllowed  = "allowed"
+	licenseDenied   = "denied"
+	licenseUnlisted = "not allowed"
+	licenseUnknown  = "unknown"
+	licenseIgnored  = "ignored"
+)
+
+// what an installed package is licensed under and what the project's policy makes of it
+type licenseEntry struct {
+	name    string
+	version string
+	license string
+	from    string
+	status  string
+}
+
+func (e licenseEntry) violation() bool {
+	return e.status == licenseDenied || e.status == licenseUnlisted
+}
+
+// trove classifiers predate SPDX, the common ones are mapped so a policy can name them the SPDX way
+var licenseClassifiers = map[string]string{
+	"MIT License":                                             "MIT",
+	"MIT No Attribution License (MIT-0)":                      "MIT-0",
+	"BSD License":                                             "BSD",
+	"Apache Software License":                                 "Apache",
+	"ISC License (ISCL)":                                      "ISC",
+	"Python Software Foundation License":                      "PSF-2.0",
+	"Mozilla Public License 2.0 (MPL 2.0)":                    "MPL-2.0",
+	"The Unlicense (Unlicense)":                               "Unlicense",
+	"Historical Permission Notice and Disclaimer (HPND)":      "HPND",
+	"GNU General Public License v2 (GPLv2)":                   "GPL-2.0",
+	"GNU General Public License v2 or later (GPLv2+)":         "GPL-2.0-or-later",
+	"GNU General Public License v3 (GPLv3)":                   "GPL-3.0",
+	"GNU General Public License v3 or later (GPLv3+)":         "GPL-3.0-or-later",
+	"GNU Lesser General Public License v2 (LGPLv2)":           "LGPL-2.0",
+	"GNU Lesser General Public License v2 or later (LGPLv2+)": "LGPL-2.0-or-later",
+	"GNU Lesser General Public License v3 (LGPLv3)":           "LGPL-3.0",
+	"GNU Lesser General Public License v3 or later (LGPLv3+)": "LGPL-3.0-or-later",
+	"GNU Affero General Public License v3":                    "AGPL-3.0",
+	"GNU Affero General Public License v3 or later (AGPLv3+)": "AGPL-3.0-or-later",
+	"Eclipse Public License 2.0 (EPL-2.0)":                    "EPL-2.0",
+}
+
+// License-Expression (PEP 639) wins, then a License field short enough to be a name rather than
+// the full text, then the classifiers, which are read as alternatives
+func distributionLicense(headers map[string][]string) (string, string) {
+	if expression := firstHeader(headers, "License-Expression"); expression != "" {
+		return expression, "License-Expression"
+	}
+
+	var license = firstHeader(headers, "License")
+	if license != "" && !strings.Contains(license, "\n") && len(license) <= 40 && !strings.EqualFold(license, "UNKNOWN") {
+		return license, "License"
+	}
+
+	var names []string
+	for _, classifier := range headers["Classifier"] {
+		var rest, found = strings.CutPrefix(classifier, "License :: ")
+		if !found {
+			continue
+		}
+		var parts = strings.Split(rest, " :: ")
+		var name = parts[len(parts)-1]
+		if name == "OSI Approved" {
+			continue
+		}
+		if spdx, ok := licenseClassifiers[name]; ok {
+			name = spdx
+		}
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		return strings.Join(names, " OR "), "Classifier"
+	}
+	return "", ""
+}
+
+// a policy entry matches case insensitively, a trailing * matches any suffix so "GPL-*" covers every GPL
+func licenseMatches(patterns []string, license string) bool {
+	for _, pattern := range patterns {
+		var p, l = strings.ToLower(strings.TrimSpace(pattern)), strings.ToLower(license)
+		if prefix, wildcard := strings.CutSuffix(p, "*"); wildcard && strings.HasPrefix(l, prefix) {
+			return true
+		}
+		if p == l {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluates an SPDX style expression, OR needs one acceptable side and AND needs both
+func licenseStatus(policy licensePolicy, expression string) string {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return licenseUnknown
+	}
+	for strings.HasPrefix(expression, "(") && strings.HasSuffix(expression, ")") && balancedParens(expression[1:len(expression)-1]) {
+		expression = strings.TrimSpace(expression[1 : len(expression)-1])
+	}
+
+	if alternatives := splitLicenseExpression(expression, " OR "); len(alternatives) > 1 {
+		var status = licenseDenied
+		for _, alternative := range alternatives {
+			switch licenseStatus(policy, alternative) {
+			case licenseAllowed:
+				return licenseAllowed
+			case licenseUnlisted, licenseUnknown:
+				status = licenseUnlisted
+			}
+		}
+		return status
+	}
+
+	if required := splitLicenseExpression(expression, " AND "); len(required) > 1 {
+		var status = licenseAllowed
+		for _, part := range required {
+			switch licenseStatus(policy, part) {
+			case licenseDenied:
+				return licenseDenied
+			case licenseUnlisted, licenseUnknown:
+				status = licenseUnlisted
+			}
+		}
+		return status
+	}
+
+	// "GPL-2.0-only WITH Classpath-exception-2.0" is judged as a whole first, then by its license
+	var license, _, _ = strings.Cut(expression, " WITH ")
+	switch {
+	case licenseMatches(policy.Deny, expression), licenseMatches(policy.Deny, license):
+		return licenseDenied
+	case len(policy.Allow) == 0, licenseMatches(policy.Allow, expression), licenseMatches(policy.Allow, license):
+		return licenseAllowed
+	}
+	return licenseUnlisted
+}
+
+// splits on a keyword outside parentheses, the keyword is matched case insensitively
+func splitLicenseExpression(expression string, keyword string) []string {
+	var parts []string
+	var depth, start int
+	var upper = strings.ToUpper(expression)
+	for i := 0; i < len(expression); i++ {
+		switch expression[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(upper[i:], keyword) {
+			parts = append(parts, strings.TrimSpace(expression[start:i]))
+			start = i + len(keyword)
+			i += len(keyword) - 1
+		}
+	}
+	return append(parts, strings.TrimSpace(expression[start:]))
+}
+
+func balancedParens(s string) bool {
+	var depth int
+	for _, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			return false
+		}
+	}
+	return depth == 0
+}
+
+// every distribution in the environment's site-packages, violations first
+func checkLicenses(env pythonEnv, policy licensePolicy) ([]licenseEntry, error) {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return nil, err
+	}
+
+	var ignored = make(map[string]bool)
+	for _, name := range policy.Ignore {
+		ignored[pep.Normalize(name)] = true
+	}
+
+	var entries []licenseEntry
+	for _, dist := range readInstalledDistributions(dirs) {
+		var entry = licenseEntry{name: dist.name, version: dist.version, license: dist.license, from: dist.licenseFrom}
+		entry.status = licenseStatus(policy, dist.license)
+		if ignored[pep.Normalize(dist.name)] {
+			entry.status = licenseIgnored
+		}
+		entries = append(entries, entry)
+	}
+
+	var rank = map[string]int{licenseDenied: 0, licenseUnlisted: 1, licenseUnknown: 2}
+	sort.SliceStable(entries, func(i, j int) bool {
+		var a, aok = rank[entries[i].status]
+		var b, bok = rank[entries[j].status]
+		if !aok {
+			a = len(rank)
+		}
+		if !bok {
+			b = len(rank)
+		}
+		if a != b {
+			return a < b
+		}
+		return strings.ToLower(entries[i].name) < strings.ToLower(entries[j].name)
+	})
+	return entries, nil
+}
+
+// the format follows the extension, anything that isn't .json is written as CSV
+func exportLicenses(path string, entries []licenseEntry) error {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		type exported struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+			License string `json:"license"`
+			Source  string `json:"source"`
+			Status  string `json:"status"`
+		}
+		var rows = make([]exported, 0, len(entries))
+		for _, entry := range entries {
+			rows = append(rows, exported{entry.name, entry.version, entry.license, entry.from, entry.status})
+		}
+		var data, err = json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, append(data, '\n'), 0644)
+	}
+
+	var file, err = os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var w = csv.NewWriter(file)
+	w.Write([]string{"name", "version", "license", "source", "status"})
+	for _, entry := range entries {
+		w.Write([]string{entry.name, entry.version, entry.license, entry.from, entry.status})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("%v: %w", path, err)
+	}
+	return file.Close()
+}
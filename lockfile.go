@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"lazypython/pep"
+)
+
+const (
+	lockKindUv     = "uv.lock"
+	lockKindPoetry = "poetry.lock"
+	lockKindPipenv = "Pipfile.lock"
+	lockKindPdm    = "pdm.lock"
+)
+
+// the pinned versions of a lockfile keyed by normalized name, the first lockfile found wins
+type lockFile struct {
+	kind     string
+	versions map[string]string
+}
+
+func (l lockFile) found() bool {
+	return l.kind != ""
+}
+
+func (l lockFile) version(name string) (string, bool) {
+	var version, ok = l.versions[pep.Normalize(name)]
+	return version, ok
+}
+
+func readLockFile() (lockFile, error) {
+	for _, kind := range []string{lockKindUv, lockKindPoetry, lockKindPdm, lockKindPipenv} {
+		var data, err = os.ReadFile(kind)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return lockFile{}, err
+		}
+
+		var lock = lockFile{kind: kind, versions: make(map[string]string)}
+		switch kind {
+		case lockKindPipenv:
+			err = parsePipfileLock(data, &lock)
+		default:
+			err = parseTomlLock(data, &lock)
+		}
+		if err != nil {
+			return lockFile{}, fmt.Errorf("%v: %w", kind, err)
+		}
+		return lock, nil
+	}
+	return lockFile{}, nil
+}
+
+// uv.lock, poetry.lock and pdm.lock all keep one [[package]] table per locked distribution
+func parseTomlLock(data []byte, lock *lockFile) error {
+	var doc struct {
+		Package []struct {
+			Name    string
+			Version string
+			Source  map[string]any
+		}
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	for _, pkg := range doc.Package {
+		// the project itself and path dependencies aren't pinned to anything on an index
+		if _, ok := pkg.Source["editable"]; ok {
+			continue
+		}
+		if _, ok := pkg.Source["virtual"]; ok {
+			continue
+		}
+		lock.add(pkg.Name, pkg.Version)
+	}
+	return nil
+}
+
+func parsePipfileLock(data []byte, lock *lockFile) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	for _, section := range []string{"default", "develop"} {
+		var pkgs map[string]struct {
+			Version string `json:"version"`
+		}
+		if raw, ok := doc[section]; ok {
+			if err := json.Unmarshal(raw, &pkgs); err != nil {
+				return err
+			}
+		}
+		for name, pkg := range pkgs {
+			lock.add(name, strings.TrimPrefix(pkg.Version, "=="))
+		}
+	}
+	return nil
+}
+
+// forked uv resolutions can lock a package more than once, the first entry is kept
+func (l *lockFile) add(name string, version string) {
+	var key = pep.Normalize(name)
+	if _, ok := l.versions[key]; ok || version == "" {
+		return
+	}
+	l.versions[key] = version
+}
+
+// installed at a different version than locked, or declared, locked and not installed. locked
+// packages nobody lists aren't flagged, a lock covers every platform and most of those never install here
+func lockDrift(pkg pythonPackage, lock lockFile) bool {
+	var locked, ok = lock.version(pkg.path)
+	if !ok {
+		return false
+	}
+	return !pkg.installed || compareVersions(pkg.version, locked) != 0
+}
+
+func driftedPackages(pkgs []pythonPackage, lock lockFile) []string {
+	var drifted []string
+	for _, pkg := range pkgs {
+		if lockDrift(pkg, lock) {
+			drifted = append(drifted, pkg.path)
+		}
+	}
+	return drifted
+}
+
+// the locked pins of everything that drifted, for managers that can't read the lock themselves
+func lockPins(pkgs []pythonPackage, lock lockFile) []string {
+	var pins []string
+	for _, name := range driftedPackages(pkgs, lock) {
+		var version, _ = lock.version(name)
+		pins = append(pins, fmt.Sprintf("%v==%v", name, version))
+	}
+	sort.Strings(pins)
+	return pins
+}
+
+func lockOwner(kind string) packageManager {
+	switch kind {
+	case lockKindUv:
+		return uvManager{}
+	case lockKindPoetry:
+		return poetryManager{}
+	case lockKindPdm:
+		return pdmManager{}
+	}
+	return nil
+}
+
+// the manager that wrote the lock syncs to it best when it's installed, otherwise the active one
+// installs the locked pins of whatever drifted
+func runSyncToLockCommandAndRespond(manager packageManager, env pythonEnv, lock lockFile, pkgs []pythonPackage) InstallResponseObject {
+	var target = syncTarget{lock: lock, pins: lockPins(pkgs, lock)}
+	if owner := lockOwner(lock.kind); owner != nil && owner.available(env) {
+		return owner.sync(env, target)
+	}
+	if lock.kind == lockKindPipenv && onPath("pipenv") {
+		return runCommandAndRespond(toolCommand("pipenv", env, "sync", "--dev"))
+	}
+	return manager.sync(env, target)
+}
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -65,3 +69,18 @@ func drawLoggingPage(m *model) string {
 		footer,
 	)
 }
+
+func addLog(m *model, level string, message string) {
+	var tnow = time.Now()
+	m.logs = append(m.logs, LogObject{Level: level, Time: fmt.Sprintf("%v-%v-%v", tnow.Hour(), tnow.Minute(), tnow.Second()),
+		Message: message})
+}
+
+// command output can span many lines, log each one so the table stays readable
+func addLogLines(m *model, level string, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			addLog(m, level, line)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
@@ -12,13 +13,19 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"lazypython/pep"
 )
 
-var remotePackagesIndexedSuccessfully = false
-
 type pythonPackage struct {
-	path    string
-	version string
+	path      string
+	version   string
+	summary   string
+	requires  []string
+	license   string
+	installer string
+	editable  bool
+	installed bool
+	sources   []string
 }
 
 type pythonScript struct {
@@ -38,6 +45,11 @@ type pythonManager struct {
 	version  string
 	packages []pythonPackage
 	scripts  []pythonScript
+	lock     lockFile
+	// the project's requires-python range
+	requiresPython string
+	// requirements file lines that couldn't be turned into a package
+	skippedRequirements []string
 }
 
 type dimension struct {
@@ -52,7 +64,7 @@ type model struct {
 	loadingState                      bool
 	spinner                           spinner.Model
 	info                              string
-	managerInUse                      string
+	managerInUse                      packageManager
 	openHelpMenu                      bool
 	openPackageInstallScreen          bool
 	packageInput                      textinput.Model
@@ -62,6 +74,12 @@ type model struct {
 	focusedOnRemotePackageTable       bool
 	remotePackageTableIndex           int
 	remotePackagesIndexedSuccessfully bool
+	remotePackages                    []string
+	index                             indexClient
+	packageInfoLoading                string
+	packageInfoErr                    error
+	screenCtx                         context.Context
+	cancelScreen                      context.CancelFunc
 	localPackages                     []pythonPackage
 	pythonScriptTable                 table.Model
 	focusOnLocalPackageTable          bool
@@ -69,9 +87,67 @@ type model struct {
 	logs                              []LogObject
 	showLoggingScreen                 bool
 	logTable                          table.Model
+	confirmDialog                     *confirmDialog
+	latestVersions                    map[string]string
+	selectedPackages                  map[string]bool
+	versionTable                      table.Model
+	openVersionPicker                 bool
+	packageSource                     string
+	activeEnv                         pythonEnv
+	pythonVersion                     string
+	environments                      []pythonEnv
+	venvTable                         table.Model
+	showVenvScreen                    bool
+	showVenvWizard                    bool
+	venvWizard                        venvWizard
+	showTreeScreen                    bool
+	depTree                           treeView
+	promptDialog                      *promptDialog
+	showDependencyScreen              bool
+	dependencyTable                   table.Model
+	dependencyRows                    []dependencyRow
+	lockFile                          lockFile
+	showToolsScreen                   bool
+	toolsTable                        table.Model
+	tools                             []pythonTool
+	toolsLoading                      bool
+	toolBackend                       string
+	requiresPython                    string
+	showInterpreterScreen             bool
+	interpreterTable                  table.Model
+	interpreters                      []pythonInterpreter
+	interpretersLoading               bool
+	showCompatScreen                  bool
+	compatTable                       table.Model
+	compatEntries                     []compatEntry
+	compatLoading                     bool
+	compatProblemsOnly                bool
+	showAuditScreen                   bool
+	auditTable                        table.Model
+	auditFindings                     []auditFinding
+	auditLoading                      bool
+	auditUpdated                      time.Time
+	indexCache                        PackageCache
+	indexUpdated                      time.Time
+	indexRefreshing                   bool
+	showLicenseScreen                 bool
+	licenseTable                      table.Model
+	licenseEntries                    []licenseEntry
+	licensesLoading                   bool
+	licenseViolationsOnly             bool
+	showHealthScreen                  bool
+	healthTable                       table.Model
+	healthIssues                      []healthIssue
+	healthLoading                     bool
 }
 
-type InfoMsg string
+type PackageIndexMsg struct {
+	cache   PackageCache
+	changed bool
+	// read from disk, not checked against the index yet
+	cached bool
+	err    error
+}
 
 func updateSpinnerType(m *model) {
 	var spinners = []spinner.Spinner{spinner.Dot, spinner.Globe, spinner.Line, spinner.MiniDot, spinner.Jump, spinner.Ellipsis, spinner.Meter, spinner.Monkey, spinner.Moon, spinner.Points, spinner.Pulse}
@@ -88,7 +164,15 @@ func initialize() model {
 	installEntry.CharLimit = -1
 	installEntry.Focus()
 	installEntry.Placeholder = "Enter package name..."
-	var m = model{spinner: _spinner, info: "Hello from Lazypython", packageInput: installEntry, showHomeScreen: true, managerInUse: "pip", focusOnLocalPackageTable: true}
+	var m = model{spinner: _spinner, info: "Hello from Lazypython", packageInput: installEntry, showHomeScreen: true, focusOnLocalPackageTable: true, packageSource: packageSourcePip, activeEnv: defaultPythonEnv()}
+	m.managerInUse = detectManager(m.activeEnv)
+	var cfg, err = loadIndexConfig()
+	indexes = cfg
+	m.index = newIndexClient(cfg)
+	if err != nil {
+		m.err = err
+		m.info = fmt.Sprintf("err: %v", err.Error())
+	}
 	updateSpinnerType(&m)
 
 	return m
@@ -98,42 +182,283 @@ func (m model) Init() tea.Cmd {
 	return tea.Batch(m.spinner.Tick, fetchPackagesFromindexAsync(&m))
 }
 
+type LatestVersionsMsg map[string]string
+
+type RequirementsExportedMsg struct {
+	path string
+	err  error
+}
+
+type PackageInfoMsg struct {
+	name string
+	info PackageInfo
+	err  error
+}
+
 type LoadedPythonManager struct {
 	pacman pythonManager
 	err    error
 }
 
+// a cached list of any age can be searched straight away, only a missing one is waited for
 func fetchPackagesFromindexAsync(m *model) tea.Cmd {
+	var client = m.index
 	return func() tea.Msg {
-		if ok := toCacheOrNotToCache(); ok {
-			return InfoMsg("Packages loaded from cache!")
+		if cache, ok := loadPackagesFromCache(); ok {
+			return PackageIndexMsg{cache: cache, cached: true}
 		}
-		fetchPackagesFromIndex()
-		remotePackagesIndexedSuccessfully = true
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		var cache, changed, err = refreshPackageCache(ctx, client, PackageCache{})
+		return PackageIndexMsg{cache: cache, changed: changed, err: err}
+	}
+}
 
-		savePackagesToCache(pythonPackages)
-		return InfoMsg("Remote packages indexed successfully!")
+// the old list stays in use until the refreshed one arrives
+func refreshPackageIndexAsync(m *model) tea.Cmd {
+	m.indexRefreshing = true
+	var client = m.index
+	var cache = m.indexCache
+	return func() tea.Msg {
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		var refreshed, changed, err = refreshPackageCache(ctx, client, cache)
+		return PackageIndexMsg{cache: refreshed, changed: changed, err: err}
 	}
 }
 
-func fetchPackagesAsync() tea.Cmd {
+// the details of the package picked on the install screen, dropped when the user leaves it
+func fetchPackageInfoAsync(m *model, name string) tea.Cmd {
+	m.packageInfoLoading = name
+	m.info = fmt.Sprintf("%v Loading %v...", m.spinner.View(), name)
+	var client = m.index
+	var ctx = screenContext(m)
 	return func() tea.Msg {
-		var pman, err = generatePackageDetails()
+		var ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		var info, err = getPackageInfo(ctx, client, name)
+		return PackageInfoMsg{name: name, info: info, err: err}
+	}
+}
+
+func fetchPackagesAsync(m *model) tea.Cmd {
+	var source = m.packageSource
+	var env = m.activeEnv
+	var manager = m.managerInUse
+	return func() tea.Msg {
+		var pman, err = generatePackageDetails(manager, env, source)
 		return LoadedPythonManager{pacman: pman, err: err}
 	}
 }
 
-func runInstallCommandAndRespondAsync(m *model) tea.Cmd {
-	m.info = fmt.Sprintf("%v Installing %v...", m.spinner.View(), m.remotePackageTable.SelectedRow()[0])
+// the selected package plus whatever extras / specifier were typed after the name
+func installRequirement(m *model) string {
+	var _, suffix = splitRequirementInput(m.packageInput.Value())
+	return m.remotePackageTable.SelectedRow()[0] + suffix
+}
+
+func runInstallCommandAndRespondAsync(m *model, requirement string) tea.Cmd {
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	m.info = fmt.Sprintf("%v Installing %v...", m.spinner.View(), requirement)
 	return func() tea.Msg {
-		var res = runInstallCommandAndRespond(m.managerInUse, m.remotePackageTable.SelectedRow()[0])
+		var res = manager.install(env, requirement)
+		if !res.isErr {
+			if err := declareInstalledDependency(manager, requirement); err != nil {
+				res.content += fmt.Sprintf("\ninstalled but %v was not updated: %v", pyprojectFile, err.Error())
+			}
+		}
 		return res
 	}
 }
 
+// resolves the install without running it, the confirm dialog then shows what it would change
+func previewInstallAsync(m *model, requirement string) tea.Cmd {
+	var previewer, ok = m.managerInUse.(installPreviewer)
+	if !ok {
+		openConfirmDialog(m, fmt.Sprintf("Install %v using %v? (%v has no dry run to preview)", requirement, m.managerInUse, m.managerInUse), func(m *model) tea.Cmd {
+			return runInstallCommandAndRespondAsync(m, requirement)
+		})
+		return nil
+	}
+
+	var env = m.activeEnv
+	var pkgs = m.localPackages
+	var client = m.index
+	var ctx = screenContext(m)
+	m.info = fmt.Sprintf("%v Resolving %v...", m.spinner.View(), requirement)
+	return func() tea.Msg {
+		var preview, err = previewInstall(ctx, client, previewer, env, pkgs, requirement)
+		return InstallPreviewMsg{preview: preview, err: err, ctx: ctx}
+	}
+}
+
+func closeVersionPicker(m *model) {
+	m.openVersionPicker = false
+	m.versionTable.Blur()
+	if m.focusedOnRemotePackageTable {
+		m.remotePackageTable.Focus()
+	} else {
+		m.packageInput.Focus()
+	}
+}
+
+func fetchLatestVersionsAsync(m *model, pkgs []pythonPackage) tea.Cmd {
+	var client = m.index
+	return func() tea.Msg {
+		var ctx, cancel = context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		return LatestVersionsMsg(fetchLatestVersions(ctx, client, pkgs))
+	}
+}
+
+// every upgrade runs as its own command so each result lands in the logs separately
+func runUpgradeCommandsAndRespondAsync(m *model, pkgs []string) tea.Cmd {
+	m.info = fmt.Sprintf("%v Upgrading %v package(s)...", m.spinner.View(), len(pkgs))
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	var cmds []tea.Cmd
+	for _, pkg := range pkgs {
+		cmds = append(cmds, func() tea.Msg {
+			return UpgradeResponseObject{pkg: pkg, res: manager.upgrade(env, pkg)}
+		})
+	}
+	cmds = append(cmds, fetchPackagesAsync(m))
+	return tea.Sequence(cmds...)
+}
+
+func exportRequirementsAsync(m *model, path string, withHashes bool) tea.Cmd {
+	var env = m.activeEnv
+	var pkgs = m.localPackages
+	var client = m.index
+	m.info = fmt.Sprintf("%v Exporting to %v...", m.spinner.View(), path)
+	return func() tea.Msg {
+		var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		return RequirementsExportedMsg{path: path, err: exportRequirements(ctx, client, path, env, pkgs, withHashes)}
+	}
+}
+
+func selectedLocalPackage(m *model) (pythonPackage, bool) {
+	var cursor = m.packageTable.Cursor()
+	if cursor < 0 || cursor >= len(m.localPackages) {
+		return pythonPackage{}, false
+	}
+	return m.localPackages[cursor], true
+}
+
+func outdatedPackages(m *model) []string {
+	var pkgs []string
+	for _, pkg := range m.localPackages {
+		if isNewerVersion(m.latestVersions[pkg.path], pkg.version) {
+			pkgs = append(pkgs, pkg.path)
+		}
+	}
+	return pkgs
+}
+
+func runUninstallCommandAndRespondAsync(m *model, pkg string) tea.Cmd {
+	m.info = fmt.Sprintf("%v Uninstalling %v...", m.spinner.View(), pkg)
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	return func() tea.Msg {
+		var res = manager.uninstall(env, pkg)
+		if !res.isErr {
+			if err := undeclareRemovedDependency(manager, pkg); err != nil {
+				res.content += fmt.Sprintf("\nuninstalled but %v was not updated: %v", pyprojectFile, err.Error())
+			}
+		}
+		return UninstallResponseObject{pkg: pkg, res: res}
+	}
+}
+
+func runSyncToLockCommandAndRespondAsync(m *model) tea.Cmd {
+	m.info = fmt.Sprintf("%v Syncing the environment to %v...", m.spinner.View(), m.lockFile.kind)
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	var lock = m.lockFile
+	var pkgs = m.localPackages
+	return tea.Sequence(func() tea.Msg {
+		return SyncResponseObject{lock: lock.kind, res: runSyncToLockCommandAndRespond(manager, env, lock, pkgs)}
+	}, fetchPackagesAsync(m))
+}
+
+func runLockCommandAndRespondAsync(m *model) tea.Cmd {
+	m.info = fmt.Sprintf("%v Locking with %v...", m.spinner.View(), m.managerInUse)
+	var manager = m.managerInUse
+	var env = m.activeEnv
+	return tea.Sequence(func() tea.Msg {
+		return LockResponseObject{manager: manager.String(), res: manager.lock(env)}
+	}, fetchPackagesAsync(m))
+}
+
+// index requests made for the screen on show share this context, it's canceled when the user leaves
+func screenContext(m *model) context.Context {
+	if m.screenCtx == nil {
+		m.screenCtx, m.cancelScreen = context.WithCancel(context.Background())
+	}
+	return m.screenCtx
+}
+
+func cancelScreenRequests(m *model) {
+	if m.cancelScreen != nil {
+		m.cancelScreen()
+	}
+	m.screenCtx, m.cancelScreen = nil, nil
+	m.packageInfoLoading = ""
+}
+
+func onHomeScreen(m *model) bool {
+	return m.showHomeScreen && !m.openHelpMenu && !m.openPackageInstallScreen && !m.showLoggingScreen
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirmDialog != nil && msg.String() != "ctrl+c" {
+			return m, updateConfirmDialog(&m, msg)
+		}
+
+		if m.promptDialog != nil && msg.String() != "ctrl+c" {
+			return m, updatePromptDialog(&m, msg)
+		}
+
+		if m.showVenvWizard && msg.String() != "ctrl+c" {
+			return m, updateVenvWizard(&m, msg)
+		}
+
+		if m.showTreeScreen && msg.String() != "ctrl+c" {
+			return m, updateTreeScreen(&m, msg)
+		}
+
+		if m.showDependencyScreen && msg.String() != "ctrl+c" {
+			return m, updateDependencyScreen(&m, msg)
+		}
+
+		if m.showToolsScreen && msg.String() != "ctrl+c" {
+			return m, updateToolsScreen(&m, msg)
+		}
+
+		if m.showInterpreterScreen && msg.String() != "ctrl+c" {
+			return m, updateInterpreterScreen(&m, msg)
+		}
+
+		if m.showCompatScreen && msg.String() != "ctrl+c" {
+			return m, updateCompatScreen(&m, msg)
+		}
+
+		if m.showAuditScreen && msg.String() != "ctrl+c" {
+			return m, updateAuditScreen(&m, msg)
+		}
+
+		if m.showLicenseScreen && msg.String() != "ctrl+c" {
+			return m, updateLicenseScreen(&m, msg)
+		}
+
+		if m.showHealthScreen && msg.String() != "ctrl+c" {
+			return m, updateHealthScreen(&m, msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
@@ -146,12 +471,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "p":
-			if m.showHomeScreen {
-				if m.managerInUse == "pip" {
-					m.managerInUse = "uv"
-				} else {
-					m.managerInUse = "pip"
-				}
+			if onHomeScreen(&m) {
+				m.managerInUse = nextManager(m.managerInUse, m.activeEnv)
+				m.info = fmt.Sprintf("Using %v", m.managerInUse)
+				return m, fetchPackagesAsync(&m)
 			}
 
 		case "ctrl+p":
@@ -160,15 +483,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showPackageTable = false
 			if m.openPackageInstallScreen {
 				m.showHomeScreen = true
+			} else {
+				cancelScreenRequests(&m)
 			}
 
 		case "esc":
+			if m.openVersionPicker {
+				closeVersionPicker(&m)
+				break
+			}
 			if m.openHelpMenu {
 				m.openHelpMenu = false
 			}
 			if m.openPackageInstallScreen {
 				m.openPackageInstallScreen = false
 				m.remotePackageSelected = PackageInfo{}
+				m.packageInfoErr = nil
+				cancelScreenRequests(&m)
 			}
 
 			if !m.openHelpMenu || !m.openPackageInstallScreen {
@@ -179,9 +510,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.showLoggingScreen = false
 			}
 
+			if m.showVenvScreen {
+				m.showVenvScreen = false
+			}
+
 		case "ctrl+l":
 			m.showLoggingScreen = !m.showLoggingScreen
 			m.showHomeScreen = false
+			if m.openPackageInstallScreen {
+				cancelScreenRequests(&m)
+			}
 			m.openPackageInstallScreen = false
 			m.openHelpMenu = false
 			if !m.showLoggingScreen {
@@ -190,7 +528,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			updateLoggingTable(&m)
 
 		case "down":
-			if m.openPackageInstallScreen {
+			if m.openPackageInstallScreen && !m.openVersionPicker {
 				if m.packageInput.Focused() {
 					m.packageInput.Blur()
 					m.remotePackageTable.Focus()
@@ -200,7 +538,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "up":
-			if m.openPackageInstallScreen {
+			if m.openPackageInstallScreen && !m.openVersionPicker {
 				if m.remotePackageTable.Cursor() < 1 && m.remotePackageTable.Focused() {
 					m.focusedOnRemotePackageTable = false
 					m.remotePackageTable.Blur()
@@ -236,19 +574,255 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "enter":
+			if m.showVenvScreen {
+				var cursor = m.venvTable.Cursor()
+				if cursor >= 0 && cursor < len(m.environments) {
+					m.activeEnv = m.environments[cursor]
+					m.showVenvScreen = false
+					m.showHomeScreen = true
+					m.latestVersions = nil
+					m.info = fmt.Sprintf("Switched to %v", m.activeEnv.name)
+					m.loadingState = true
+					return m, fetchPackagesAsync(&m)
+				}
+			}
+			if m.openVersionPicker {
+				if len(m.versionTable.SelectedRow()) > 0 {
+					var name, suffix = splitRequirementInput(m.packageInput.Value())
+					var extras string
+					if strings.HasPrefix(suffix, "[") && strings.Contains(suffix, "]") {
+						extras = suffix[:strings.Index(suffix, "]")+1]
+					}
+					if name == "" {
+						name = m.remotePackageSelected.Info.Name
+					}
+					m.packageInput.SetValue(fmt.Sprintf("%v%v==%v", name, extras, m.versionTable.SelectedRow()[0]))
+				}
+				closeVersionPicker(&m)
+				break
+			}
 			if m.openPackageInstallScreen {
-				if m.remotePackageTable.Focused() {
-					m.remotePackageSelected = getPackageInfo(m.remotePackageTable.SelectedRow()[0])
+				if m.remotePackageTable.Focused() && len(m.remotePackageTable.SelectedRow()) > 0 {
+					return m, fetchPackageInfoAsync(&m, m.remotePackageTable.SelectedRow()[0])
 				}
 			}
 
+		case "ctrl+r":
+			if m.openPackageInstallScreen && !m.openVersionPicker {
+				if len(m.remotePackageSelected.Releases) == 0 {
+					m.info = "Press Enter on a package first to load its releases"
+					break
+				}
+				drawVersionTable(&m, m.remotePackageSelected)
+				m.openVersionPicker = true
+				m.packageInput.Blur()
+				m.remotePackageTable.Blur()
+			}
+
 		case "ctrl+a":
 			if m.openPackageInstallScreen {
-				if m.remotePackageTable.Focused() {
-					return m, runInstallCommandAndRespondAsync(&m)
+				if m.remotePackageTable.Focused() && len(m.remotePackageTable.SelectedRow()) > 0 {
+					if _, err := pep.ParseRequirement(installRequirement(&m)); err != nil {
+						m.info = fmt.Sprintf("err: %v", err.Error())
+						break
+					}
+					return m, previewInstallAsync(&m, installRequirement(&m))
 				}
 			}
 
+		case "ctrl+t":
+			if m.openPackageInstallScreen && m.remotePackageTable.Focused() && len(m.remotePackageTable.SelectedRow()) > 0 {
+				if m.toolBackend == "" {
+					m.toolBackend = defaultToolBackend()
+				}
+				if m.toolBackend == "" {
+					m.info = "Neither uv nor pipx is installed"
+					break
+				}
+				var backend = m.toolBackend
+				var requirement = installRequirement(&m)
+				openConfirmDialog(&m, fmt.Sprintf("Install %v as a tool using %v?", requirement, backend), func(m *model) tea.Cmd {
+					return runToolCommandAndRespondAsync(m, "install", backend, requirement)
+				})
+			}
+
+		case "ctrl+o":
+			if m.openPackageInstallScreen && m.remotePackageTable.Focused() && len(m.remotePackageTable.SelectedRow()) > 0 {
+				openRunOncePrompt(&m, m.remotePackageTable.SelectedRow()[0])
+			}
+
+		case "x":
+			if onHomeScreen(&m) && m.focusOnLocalPackageTable {
+				var selected, ok = selectedLocalPackage(&m)
+				if !ok {
+					break
+				}
+				var pkg = selected.path
+				openConfirmDialog(&m, fmt.Sprintf("Uninstall %v using %v?", pkg, m.managerInUse), func(m *model) tea.Cmd {
+					return runUninstallCommandAndRespondAsync(m, pkg)
+				})
+			}
+
+		case "s":
+			if onHomeScreen(&m) && m.focusOnLocalPackageTable {
+				if selected, ok := selectedLocalPackage(&m); ok {
+					if m.selectedPackages == nil {
+						m.selectedPackages = make(map[string]bool)
+					}
+					if m.selectedPackages[selected.path] {
+						delete(m.selectedPackages, selected.path)
+					} else {
+						m.selectedPackages[selected.path] = true
+					}
+					updatePythonPackageTable(&m)
+				}
+			}
+
+		case "U":
+			if onHomeScreen(&m) && m.focusOnLocalPackageTable {
+				var pkgs []string
+				for _, pkg := range m.localPackages {
+					if m.selectedPackages[pkg.path] {
+						pkgs = append(pkgs, pkg.path)
+					}
+				}
+				if len(pkgs) == 0 {
+					if selected, ok := selectedLocalPackage(&m); ok {
+						pkgs = append(pkgs, selected.path)
+					}
+				}
+				if len(pkgs) == 0 {
+					break
+				}
+				openConfirmDialog(&m, fmt.Sprintf("Upgrade %v using %v?", strings.Join(pkgs, ", "), m.managerInUse), func(m *model) tea.Cmd {
+					m.selectedPackages = nil
+					return runUpgradeCommandsAndRespondAsync(m, pkgs)
+				})
+			}
+
+		case "m":
+			if onHomeScreen(&m) {
+				if m.packageSource == packageSourcePip {
+					m.packageSource = packageSourceMetadata
+				} else {
+					m.packageSource = packageSourcePip
+				}
+				m.info = fmt.Sprintf("Reading packages from %v", m.packageSource)
+				return m, fetchPackagesAsync(&m)
+			}
+
+		case "v":
+			if onHomeScreen(&m) {
+				m.environments = discoverEnvironments()
+				drawVenvTable(&m)
+				m.showVenvScreen = true
+				m.showHomeScreen = false
+			}
+
+		case "n":
+			if m.showVenvScreen {
+				m.venvWizard = newVenvWizard(&m)
+				m.showVenvScreen = false
+				m.showVenvWizard = true
+			}
+
+		case "t":
+			if onHomeScreen(&m) {
+				m.depTree = treeView{expanded: make(map[string]bool), loading: true}
+				m.showTreeScreen = true
+				m.showHomeScreen = false
+				return m, loadDependencyGraphAsync(&m)
+			}
+
+		case "D":
+			if onHomeScreen(&m) {
+				loadDependencyRows(&m)
+				drawDependencyTable(&m)
+				m.showDependencyScreen = true
+				m.showHomeScreen = false
+			}
+
+		case "S":
+			if onHomeScreen(&m) {
+				if !m.lockFile.found() {
+					m.info = "No uv.lock, poetry.lock or Pipfile.lock in this directory"
+					break
+				}
+				var drifted = driftedPackages(m.localPackages, m.lockFile)
+				openConfirmDialog(&m, fmt.Sprintf("Sync the environment to %v (%v package(s) drifted)?", m.lockFile.kind, len(drifted)), func(m *model) tea.Cmd {
+					return runSyncToLockCommandAndRespondAsync(m)
+				})
+			}
+
+		case "R":
+			if onHomeScreen(&m) && !m.indexRefreshing {
+				return m, refreshPackageIndexAsync(&m)
+			}
+
+		case "L":
+			if onHomeScreen(&m) {
+				openConfirmDialog(&m, fmt.Sprintf("Lock the project using %v?", m.managerInUse), func(m *model) tea.Cmd {
+					return runLockCommandAndRespondAsync(m)
+				})
+			}
+
+		case "T":
+			if onHomeScreen(&m) {
+				return m, openToolsScreen(&m)
+			}
+
+		case "i":
+			if onHomeScreen(&m) {
+				return m, openInterpreterScreen(&m)
+			}
+
+		case "c":
+			if onHomeScreen(&m) {
+				return m, openCompatScreen(&m)
+			}
+
+		case "a":
+			if onHomeScreen(&m) {
+				return m, openAuditScreen(&m)
+			}
+
+		case "l":
+			if onHomeScreen(&m) {
+				return m, openLicenseScreen(&m)
+			}
+
+		case "h":
+			if onHomeScreen(&m) {
+				return m, openHealthScreen(&m)
+			}
+
+		case "e", "E":
+			if onHomeScreen(&m) {
+				var withHashes = msg.String() == "E"
+				var title = "Export pinned requirements to"
+				if withHashes {
+					title = "Export pinned requirements (with hashes) to"
+				}
+				openPromptDialog(&m, title, "requirements.lock.txt", func(m *model, value string) tea.Cmd {
+					if strings.TrimSpace(value) == "" {
+						return nil
+					}
+					return exportRequirementsAsync(m, strings.TrimSpace(value), withHashes)
+				})
+			}
+
+		case "A":
+			if onHomeScreen(&m) {
+				var pkgs = outdatedPackages(&m)
+				if len(pkgs) == 0 {
+					m.info = "Everything is up to date!"
+					break
+				}
+				openConfirmDialog(&m, fmt.Sprintf("Upgrade all %v outdated package(s) using %v?", len(pkgs), m.managerInUse), func(m *model) tea.Cmd {
+					return runUpgradeCommandsAndRespondAsync(m, pkgs)
+				})
+			}
+
 		}
 
 	case tea.WindowSizeMsg:
@@ -259,36 +833,360 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.loadingState = true
-		return m, fetchPackagesAsync()
+		return m, fetchPackagesAsync(&m)
+
+	case UpgradeResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Upgrade of %v failed", msg.pkg))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to upgrade %v! Ctrl + L for logs", msg.pkg)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Upgraded %v", msg.pkg))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("%v upgraded successfully!", msg.pkg)
+		}
+
+	case AuditMsg:
+		// the screen was left before the database finished downloading
+		if errors.Is(msg.err, context.Canceled) {
+			break
+		}
+		m.auditLoading = false
+		m.auditFindings = msg.findings
+		m.auditUpdated = msg.updated
+		if msg.warning != nil {
+			addLog(&m, "Info", msg.warning.Error())
+			m.info = msg.warning.Error()
+		}
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawAuditTable(&m)
+
+	case AuditFixResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Upgrade of %v to %v failed", msg.pkg, msg.version))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to upgrade %v! Ctrl + L for logs", msg.pkg)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Upgraded %v to %v", msg.pkg, msg.version))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("%v upgraded to %v!", msg.pkg, msg.version)
+		}
+
+	case CompatibilityMsg:
+		m.compatLoading = false
+		m.compatEntries = msg.entries
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawCompatTable(&m)
+
+	case InterpretersLoadedMsg:
+		m.interpretersLoading = false
+		m.interpreters = msg.interpreters
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawInterpreterTable(&m)
+
+	case InterpreterInstalledMsg:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Installing Python %v failed", msg.version))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to install Python %v! Ctrl + L for logs", msg.version)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Installed Python %v", msg.version))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("Python %v installed, press v to switch to it", msg.version)
+		}
+
+	case ToolsLoadedMsg:
+		m.toolsLoading = false
+		m.tools = msg.tools
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawToolsTable(&m)
+
+	case ToolResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Tool %v of %v failed", msg.action, msg.tool))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to %v %v! Ctrl + L for logs", msg.action, msg.tool)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Tool %v of %v done", msg.action, msg.tool))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("%v: %v done!", msg.tool, msg.action)
+		}
+
+	case ToolRunFinishedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", fmt.Sprintf("%v: %v", msg.command, msg.err.Error()))
+			m.info = fmt.Sprintf("%v exited with %v", msg.command, msg.err.Error())
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Ran %v", msg.command))
+			m.info = fmt.Sprintf("Ran %v", msg.command)
+		}
+
+	case LockResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Locking with %v failed", msg.manager))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to lock with %v! Ctrl + L for logs", msg.manager)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Locked with %v", msg.manager))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("Locked with %v!", msg.manager)
+		}
+
+	case SyncResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Sync to %v failed", msg.lock))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to sync to %v! Ctrl + L for logs", msg.lock)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Synced to %v", msg.lock))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("Environment synced to %v!", msg.lock)
+		}
+
+	case GroupInstallResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Installing %v failed", msg.list))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to install %v! Ctrl + L for logs", msg.list)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Installed %v", msg.list))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("%v installed successfully!", msg.list)
+		}
+
+	case VenvCreatedMsg:
+		updateSpinnerType(&m)
+		m.venvWizard.creating = false
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = "Failed to create the environment! Ctrl + L for logs"
+			return m, nil
+		}
+		addLogLines(&m, "Info", msg.res.content)
+		m.activeEnv = msg.env
+		m.showVenvWizard = false
+		m.showHomeScreen = true
+		m.latestVersions = nil
+		m.info = fmt.Sprintf("Created and switched to %v", msg.env.name)
+		m.loadingState = true
+		return m, fetchPackagesAsync(&m)
+
+	case DependencyGraphMsg:
+		m.depTree.loading = false
+		m.depTree.graph = msg.graph
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+
+	case InstallPreviewMsg:
+		// nobody is waiting on the install screen for this plan anymore
+		if !m.openPackageInstallScreen || msg.ctx.Err() != nil {
+			break
+		}
+		var requirement = msg.preview.requirement
+		var install = func(m *model) tea.Cmd {
+			return runInstallCommandAndRespondAsync(m, requirement)
+		}
+		if msg.err != nil {
+			addLog(&m, "Error", fmt.Sprintf("Dry run of %v failed", requirement))
+			addLogLines(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("Dry run of %v failed! Ctrl + L for logs", requirement)
+			var lines = strings.Split(msg.err.Error(), "\n")
+			if len(lines) > 10 {
+				lines = lines[len(lines)-10:]
+			}
+			openDetailedConfirmDialog(&m, fmt.Sprintf("%v can't resolve %v, try installing anyway?", m.managerInUse, requirement), strings.Join(lines, "\n"), install)
+			break
+		}
+		m.info = ""
+		openDetailedConfirmDialog(&m, fmt.Sprintf("Install %v using %v?", requirement, m.managerInUse), msg.preview.String(), install)
+
+	case HealthCheckedMsg:
+		m.healthLoading = false
+		m.healthIssues = msg.issues
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawHealthTable(&m)
+
+	case HealthFixResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLog(&m, "Error", fmt.Sprintf("Fixing %v failed", msg.requirement))
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to install %v! Ctrl + L for logs", msg.requirement)
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Installed %v", msg.requirement))
+			addLogLines(&m, "Info", msg.res.content)
+			m.info = fmt.Sprintf("%v installed, checking again...", msg.requirement)
+		}
+
+	case LicensesCheckedMsg:
+		m.licensesLoading = false
+		m.licenseEntries = msg.entries
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("err: %v", msg.err.Error())
+		}
+		drawLicenseTable(&m)
+
+	case LicensesExportedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = "Export failed! Ctrl + L for logs"
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Exported the license report to %v", msg.path))
+			m.info = fmt.Sprintf("Exported the license report to %v", msg.path)
+		}
+
+	case RequirementsExportedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = "Export failed! Ctrl + L for logs"
+		} else {
+			addLog(&m, "Info", fmt.Sprintf("Exported requirements to %v", msg.path))
+			m.info = fmt.Sprintf("Exported requirements to %v", msg.path)
+		}
+
+	case LatestVersionsMsg:
+		m.latestVersions = msg
+		updatePythonPackageTable(&m)
+		if drifted := driftedPackages(m.localPackages, m.lockFile); len(drifted) > 0 {
+			m.info = fmt.Sprintf("%v package(s) drifted from %v, press S to sync", len(drifted), m.lockFile.kind)
+		} else if outdated := outdatedPackages(&m); len(outdated) > 0 {
+			m.info = fmt.Sprintf("%v package(s) outdated, press A to upgrade all", len(outdated))
+		}
 
 	case InstallResponseObject:
 		updateSpinnerType(&m)
 		if msg.isErr {
 			m.err = errors.New(msg.content)
-			var tnow = time.Now()
-			m.logs = append(m.logs, LogObject{Level: "Error", Time: fmt.Sprintf("%v-%v-%v", tnow.Hour(), tnow.Minute(), tnow.Second()),
-				Message: msg.content})
+			addLog(&m, "Error", msg.content)
 			m.info = "Failed to install package! Ctrl + L for logs"
 		} else {
+			addLogLines(&m, "Info", msg.content)
 			m.info = "Package installed successfully!"
 		}
+
+	case UninstallResponseObject:
+		updateSpinnerType(&m)
+		if msg.res.isErr {
+			m.err = errors.New(msg.res.content)
+			addLogLines(&m, "Error", msg.res.content)
+			m.info = fmt.Sprintf("Failed to uninstall %v! Ctrl + L for logs", msg.pkg)
+			return m, nil
+		}
+		addLogLines(&m, "Info", msg.res.content)
+		m.info = fmt.Sprintf("%v uninstalled successfully!", msg.pkg)
+		return m, fetchPackagesAsync(&m)
 	case LoadedPythonManager:
 		updateSpinnerType(&m)
 		drawPythonPackageTable(&m, msg.pacman)
 		drawPythonScriptsTable(&m, msg.pacman)
 		m.localPackages = msg.pacman.packages
+		m.lockFile = msg.pacman.lock
+		m.pythonVersion = strings.TrimSpace(msg.pacman.version)
+		m.requiresPython = msg.pacman.requiresPython
 		m.err = msg.err
 		if msg.err != nil {
 			m.info = fmt.Sprintf("err: %v", msg.err.Error())
 		}
+		for _, skipped := range msg.pacman.skippedRequirements {
+			addLog(&m, "Info", skipped)
+		}
 		m.loadingState = false
 		m.showPackageTable = true
+		if m.showDependencyScreen {
+			drawDependencyTable(&m)
+		}
+		if m.showAuditScreen {
+			return m, tea.Batch(fetchLatestVersionsAsync(&m, msg.pacman.packages), startAudit(&m, false))
+		}
+		return m, fetchLatestVersionsAsync(&m, msg.pacman.packages)
+
+	case PackageInfoMsg:
+		// a lookup the user moved on from, a newer one or none at all is wanted now
+		if msg.name != m.packageInfoLoading {
+			break
+		}
+		m.packageInfoLoading = ""
+		if msg.err != nil {
+			m.err = msg.err
+			m.packageInfoErr = msg.err
+			m.remotePackageSelected = PackageInfo{}
+			addLog(&m, "Error", msg.err.Error())
+			m.info = fmt.Sprintf("Failed to load %v! Ctrl + L for logs", msg.name)
+			break
+		}
+		m.packageInfoErr = nil
+		m.remotePackageSelected = msg.info
+		m.info = fmt.Sprintf("Loaded %v", msg.info.Info.Name)
 
-	case InfoMsg:
+	case PackageIndexMsg:
 		m.remotePackagesIndexedSuccessfully = true
-		m.info = string(msg)
-		if len(pythonPackages) < 50 {
-			m.info = "Indexing process failed! restart the app to resolve"
+		m.indexRefreshing = false
+		m.indexCache = msg.cache
+		m.indexUpdated = msg.cache.Timestamp
+		m.remotePackages = msg.cache.packages()
+		switch {
+		case msg.err != nil && len(m.remotePackages) == 0:
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = "Indexing process failed! press R to try again"
+		case msg.err != nil:
+			m.err = msg.err
+			addLog(&m, "Error", msg.err.Error())
+			m.info = "Failed to refresh the package index, the cached list is still in use! Ctrl + L for logs"
+		case msg.cached:
+			m.info = "Packages loaded from cache!"
+			if msg.cache.stale() {
+				return m, refreshPackageIndexAsync(&m)
+			}
+		case msg.changed:
+			addLog(&m, "Info", fmt.Sprintf("Package index refreshed, %v packages", len(m.remotePackages)))
+			m.info = "Package index refreshed!"
+		default:
+			m.info = "Package index is up to date"
 		}
 
 	default:
@@ -302,7 +1200,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		if m.openPackageInstallScreen {
 			if m.packageInput.Focused() {
-				var query = strings.TrimSpace(m.packageInput.Value())
+				var query, _ = splitRequirementInput(m.packageInput.Value())
 				m.filteredPackages = nil
 				if query != "" {
 					query = strings.ToLower(query)
@@ -311,7 +1209,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					var looseMatches []string
 					var pkgCount int
 					var nonExactCount int
-					for _, pkg := range pythonPackages {
+					for _, pkg := range m.remotePackages {
 						var pkgLower = strings.ToLower(pkg)
 						switch {
 						case query == pkgLower:
@@ -357,7 +1255,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	m.remotePackageTable, cmd = m.remotePackageTable.Update(msg)
+	if m.openVersionPicker {
+		m.versionTable, cmd = m.versionTable.Update(msg)
+	}
 	m.logTable, cmd = m.logTable.Update(msg)
+	if m.showVenvScreen {
+		m.venvTable, cmd = m.venvTable.Update(msg)
+	}
 	return m, cmd
 }
 
@@ -372,9 +1276,17 @@ func (m model) View() string {
 		return lipgloss.NewStyle().Width(m.window.width).Height(m.window.height).Align(lipgloss.Center, lipgloss.Center).Render(fmt.Sprintf("%s Loading...", m.spinner.View()))
 	}
 
+	if m.confirmDialog != nil {
+		return drawConfirmScreen(&m)
+	}
+
+	if m.promptDialog != nil {
+		return drawPromptScreen(&m)
+	}
+
 	if m.openHelpMenu {
 		return lipgloss.NewStyle().Width(m.window.width).Height(m.window.height).Align(lipgloss.Center, lipgloss.Center).
-			Render("HELP\nUse Ctrl + h or the Esc key to close this screen\nCtrl + c to exit the application\nCtrl + p to find (and install) a package\nUse p to toggle package managers while in home screen")
+			Render("HELP\nUse Ctrl + h or the Esc key to close this screen\nCtrl + c to exit the application\nCtrl + p to find (and install) a package\nCtrl + r on the install screen to pick a version, or type a specifier like requests[socks]>=2,<3\nCtrl + a installs the selection after a dry run shows what it would add, upgrade or downgrade\nUse p to cycle through the package managers installed here (pip, uv, poetry, pdm, hatch, conda), L to lock with the current one\nx to uninstall the selected package\ns to mark a package, U to upgrade marked (or selected), A to upgrade all outdated\nm to switch between pip freeze and reading site-packages metadata\nv to pick the python environment lazypython works against, n there to create a new one\nt to browse the dependency tree\ne to export pinned requirements, E to export them with hashes\nS to sync the environment to uv.lock, poetry.lock or Pipfile.lock, drifted packages are marked ! in the Locked column\nT for global CLI tools installed with uv tool or pipx, Ctrl + t on the install screen installs the selection as a tool and Ctrl + o runs it once\ni to list, install and pin (.python-version) python interpreters through uv or pyenv\nc to check every package's Requires-Python against the project's lowest supported python and the active one\na to audit the installed packages against the OSV advisory database, U there upgrades to the fixed version\nl for the license of every installed package, allow / deny lists go under [licenses] in .lazypython.toml\nh to check every installed package has the dependencies it asks for (like pip check) and fix what's broken\nPackages are looked up and installed from the indexes in [[index]] of .lazypython.toml, PIP_INDEX_URL / UV_INDEX_URL, [tool.uv] or pip.conf, R refreshes their package list (a list over six hours old is also refreshed in the background at startup)\nD to manage the dependencies, extras and dependency groups declared in pyproject.toml")
 	}
 
 	if m.openPackageInstallScreen {
@@ -389,6 +1301,46 @@ func (m model) View() string {
 		return drawLoggingPage(&m)
 	}
 
+	if m.showVenvScreen {
+		return drawVenvScreen(&m)
+	}
+
+	if m.showVenvWizard {
+		return drawVenvWizardScreen(&m)
+	}
+
+	if m.showTreeScreen {
+		return drawTreeScreen(&m)
+	}
+
+	if m.showDependencyScreen {
+		return drawDependencyScreen(&m)
+	}
+
+	if m.showToolsScreen {
+		return drawToolsScreen(&m)
+	}
+
+	if m.showInterpreterScreen {
+		return drawInterpreterScreen(&m)
+	}
+
+	if m.showCompatScreen {
+		return drawCompatScreen(&m)
+	}
+
+	if m.showAuditScreen {
+		return drawAuditScreen(&m)
+	}
+
+	if m.showLicenseScreen {
+		return drawLicenseScreen(&m)
+	}
+
+	if m.showHealthScreen {
+		return drawHealthScreen(&m)
+	}
+
 	return lipgloss.NewStyle().Width(m.window.width).Height(m.window.height).Align(lipgloss.Center, lipgloss.Center).Render("Somehow this page showed up even though it isn't supposed to, press the Esc key to return to Home... restart if this persists.")
 }
 
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+)
+
+// everything lazypython asks of a package manager, each backend turns these into its own commands
+type packageManager interface {
+	String() string
+	available(env pythonEnv) bool
+	// true when lazypython should leave pyproject.toml alone on install and uninstall, either the
+	// manager records them itself or its packages don't belong there
+	skipsPyprojectEdits() bool
+	list(env pythonEnv, source string) ([]pythonPackage, error)
+	install(env pythonEnv, requirement string) InstallResponseObject
+	uninstall(env pythonEnv, pkg string) InstallResponseObject
+	upgrade(env pythonEnv, pkg string) InstallResponseObject
+	sync(env pythonEnv, target syncTarget) InstallResponseObject
+	lock(env pythonEnv) InstallResponseObject
+}
+
+// what to sync to: one group or extra when list is set, otherwise the lockfile. requirements and
+// pins are spelled out for managers that can't read the group or the lock themselves
+type syncTarget struct {
+	list         *dependencyList
+	requirements []string
+	lock         lockFile
+	pins         []string
+}
+
+type pipManager struct{}
+type uvManager struct{}
+type poetryManager struct{}
+type pdmManager struct{}
+type hatchManager struct{}
+type condaManager struct{}
+
+// the order p cycles through them
+var packageManagers = []packageManager{pipManager{}, uvManager{}, poetryManager{}, pdmManager{}, hatchManager{}, condaManager{}}
+
+func managerByName(name string) packageManager {
+	for _, manager := range packageManagers {
+		if manager.String() == name {
+			return manager
+		}
+	}
+	return nil
+}
+
+var toolTablePattern = regexp.MustCompile(`(?m)^[ \t]*\[\[?[ \t]*tool\.(uv|poetry|pdm|hatch)[ \t]*[\].]`)
+
+// lockfiles say the most, then [tool.*] tables, then conda, pip when nothing else fits
+// or the manager that fits isn't installed
+func detectManager(env pythonEnv) packageManager {
+	var src, _ = readPyproject()
+	var tools = make(map[string]bool)
+	for _, match := range toolTablePattern.FindAllStringSubmatch(src, -1) {
+		tools[match[1]] = true
+	}
+
+	var candidates []packageManager
+	switch {
+	case fileExists(lockKindUv):
+		candidates = append(candidates, uvManager{})
+	case fileExists(lockKindPoetry):
+		candidates = append(candidates, poetryManager{})
+	case fileExists(lockKindPdm):
+		candidates = append(candidates, pdmManager{})
+	}
+	for _, name := range []string{"uv", "poetry", "pdm", "hatch"} {
+		if tools[name] {
+			candidates = append(candidates, managerByName(name))
+		}
+	}
+	if fileExists("hatch.toml") {
+		candidates = append(candidates, hatchManager{})
+	}
+	if env.kind == envKindConda || fileExists("environment.yml") || fileExists("environment.yaml") {
+		candidates = append(candidates, condaManager{})
+	}
+
+	for _, manager := range candidates {
+		if manager.available(env) {
+			return manager
+		}
+	}
+	return pipManager{}
+}
+
+// the next available manager after current, current itself when it's the only one
+func nextManager(current packageManager, env pythonEnv) packageManager {
+	var start int
+	for i, manager := range packageManagers {
+		if manager.String() == current.String() {
+			start = i
+		}
+	}
+	for i := 1; i < len(packageManagers); i++ {
+		var manager = packageManagers[(start+i)%len(packageManagers)]
+		if manager.available(env) {
+			return manager
+		}
+	}
+	return current
+}
+
+func onPath(tool string) bool {
+	var _, err = exec.LookPath(tool)
+	return err == nil
+}
+
+// pip runs as a module of the selected interpreter, uv is pointed at the environment instead
+func managerCommand(command string, env pythonEnv, args ...string) *exec.Cmd {
+	return managerCommandContext(context.Background(), command, env, args...)
+}
+
+// a managerCommand that's killed when ctx is done
+func managerCommandContext(ctx context.Context, command string, env pythonEnv, args ...string) *exec.Cmd {
+	if command == "uv" {
+		var cmd = exec.CommandContext(ctx, command, args...)
+		cmd.Env = append(os.Environ(), "UV_PYTHON="+env.interpreter)
+		if env.kind == envKindVenv && env.path != "" {
+			cmd.Env = append(cmd.Env, "UV_PROJECT_ENVIRONMENT="+env.path, "VIRTUAL_ENV="+env.path)
+		}
+		return withIndexEnv(cmd)
+	}
+
+	return withIndexEnv(exec.CommandContext(ctx, env.interpreter, append([]string{"-m", command}, args...)...))
+}
+
+// poetry, pdm and hatch work against whatever virtualenv looks active
+func toolCommand(tool string, env pythonEnv, args ...string) *exec.Cmd {
+	var cmd = exec.Command(tool, args...)
+	if env.kind == envKindVenv && env.path != "" {
+		cmd.Env = append(os.Environ(), "VIRTUAL_ENV="+env.path)
+	}
+	return withIndexEnv(cmd)
+}
+
+// runs the commands in order, stopping at the first failure, the output of all of them is kept
+func runCommandsAndRespond(cmds ...*exec.Cmd) InstallResponseObject {
+	var res InstallResponseObject
+	for _, cmd := range cmds {
+		var next = runCommandAndRespond(cmd)
+		next.content = res.content + next.content
+		res = next
+		if res.isErr {
+			break
+		}
+	}
+	return res
+}
+
+// for managers syncing to a lock that isn't theirs, the locked pins go straight into the environment
+func installPins(env pythonEnv, target syncTarget) InstallResponseObject {
+	if len(target.pins) == 0 {
+		return InstallResponseObject{content: fmt.Sprintf("environment already matches %v", target.lock.kind)}
+	}
+	return runCommandAndRespond(managerCommand("pip", env, append([]string{"install"}, target.pins...)...))
+}
+
+func installRequirements(env pythonEnv, target syncTarget) InstallResponseObject {
+	if len(target.requirements) == 0 {
+		return InstallResponseObject{content: fmt.Sprintf("%v has nothing to install", target.list)}
+	}
+	return runCommandAndRespond(managerCommand("pip", env, append([]string{"install"}, target.requirements...)...))
+}
+
+// puts requirement into the environment without declaring it, repairs are often to packages the
+// project only depends on through something else and shouldn't end up in pyproject.toml
+func installIntoEnvironment(manager packageManager, env pythonEnv, requirement string) InstallResponseObject {
+	switch manager.(type) {
+	case uvManager:
+		return runCommandAndRespond(managerCommand("uv", env, "pip", "install", requirement))
+	case condaManager:
+		return manager.install(env, requirement)
+	}
+	return runCommandAndRespond(managerCommand("pip", env, "install", requirement))
+}
+
+func (pipManager) String() string { return "pip" }
+
+func (pipManager) available(env pythonEnv) bool {
+	return exec.Command(env.interpreter, "-m", "pip", "--version").Run() == nil
+}
+
+func (pipManager) skipsPyprojectEdits() bool { return false }
+
+func (pipManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	return listInstalledPackages(env.interpreter, source)
+}
+
+func (pipManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("pip", env, "install", requirement))
+}
+
+func (pipManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("pip", env, "uninstall", "-y", pkg))
+}
+
+func (pipManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("pip", env, "install", "--upgrade", pkg))
+}
+
+// pip can't read dependency groups or a project's extras, it gets their requirements spelled out
+func (pipManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	if target.list != nil {
+		return installRequirements(env, target)
+	}
+	return installPins(env, target)
+}
+
+// pip lock (pip 25.1+) resolves the project into pylock.toml
+func (pipManager) lock(env pythonEnv) InstallResponseObject {
+	if !fileExists(pyprojectFile) {
+		return InstallResponseObject{content: "pip can only lock a project with a pyproject.toml, e exports pinned requirements instead", isErr: true}
+	}
+	return runCommandAndRespond(managerCommand("pip", env, "lock", "."))
+}
+
+func (uvManager) String() string { return "uv" }
+
+func (uvManager) available(env pythonEnv) bool { return onPath("uv") }
+
+func (uvManager) skipsPyprojectEdits() bool { return true }
+
+func (uvManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	return listInstalledPackages(env.interpreter, source)
+}
+
+func (uvManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("uv", env, "add", requirement))
+}
+
+func (uvManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("uv", env, "remove", pkg))
+}
+
+// uv add would turn transitive packages into direct dependencies, so bump the lock and sync instead
+func (uvManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandsAndRespond(
+		managerCommand("uv", env, "lock", "--upgrade-package", pkg),
+		managerCommand("uv", env, "sync"),
+	)
+}
+
+// a group or extra syncs with --inexact so whatever else is installed stays
+func (uvManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	switch {
+	case target.list != nil && target.list.isGroup():
+		return runCommandAndRespond(managerCommand("uv", env, "sync", "--inexact", "--group", target.list.key))
+	case target.list != nil && target.list.isExtra():
+		return runCommandAndRespond(managerCommand("uv", env, "sync", "--inexact", "--extra", target.list.key))
+	case target.list != nil:
+		return runCommandAndRespond(managerCommand("uv", env, "sync", "--inexact"))
+	case target.lock.kind == lockKindUv:
+		return runCommandAndRespond(managerCommand("uv", env, "sync", "--frozen"))
+	}
+	if len(target.pins) > 0 {
+		return runCommandAndRespond(managerCommand("uv", env, append([]string{"pip", "install"}, target.pins...)...))
+	}
+	return installPins(env, target)
+}
+
+func (uvManager) lock(env pythonEnv) InstallResponseObject {
+	return runCommandAndRespond(managerCommand("uv", env, "lock"))
+}
+
+func (poetryManager) String() string { return "poetry" }
+
+func (poetryManager) available(env pythonEnv) bool { return onPath("poetry") }
+
+func (poetryManager) skipsPyprojectEdits() bool { return true }
+
+func (poetryManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	return listInstalledPackages(env.interpreter, source)
+}
+
+func (poetryManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("poetry", env, "add", requirement))
+}
+
+func (poetryManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("poetry", env, "remove", pkg))
+}
+
+func (poetryManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("poetry", env, "update", pkg))
+}
+
+// poetry groups live in [tool.poetry.group.*], PEP 735 groups only work with poetry 2.2+
+func (poetryManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	switch {
+	case target.list != nil && target.list.isGroup():
+		return runCommandAndRespond(toolCommand("poetry", env, "install", "--with", target.list.key))
+	case target.list != nil && target.list.isExtra():
+		return runCommandAndRespond(toolCommand("poetry", env, "install", "--extras", target.list.key))
+	case target.list != nil:
+		return runCommandAndRespond(toolCommand("poetry", env, "install"))
+	case target.lock.kind == lockKindPoetry:
+		return runCommandAndRespond(toolCommand("poetry", env, "sync"))
+	}
+	return installPins(env, target)
+}
+
+func (poetryManager) lock(env pythonEnv) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("poetry", env, "lock"))
+}
+
+func (pdmManager) String() string { return "pdm" }
+
+func (pdmManager) available(env pythonEnv) bool { return onPath("pdm") }
+
+func (pdmManager) skipsPyprojectEdits() bool { return true }
+
+func (pdmManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	return listInstalledPackages(env.interpreter, source)
+}
+
+func (pdmManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("pdm", env, "add", requirement))
+}
+
+func (pdmManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("pdm", env, "remove", pkg))
+}
+
+func (pdmManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("pdm", env, "update", pkg))
+}
+
+// pdm treats extras and dependency groups alike, -G picks either
+func (pdmManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	switch {
+	case target.list != nil && (target.list.isGroup() || target.list.isExtra()):
+		return runCommandAndRespond(toolCommand("pdm", env, "install", "-G", target.list.key))
+	case target.list != nil:
+		return runCommandAndRespond(toolCommand("pdm", env, "install"))
+	case target.lock.kind == lockKindPdm:
+		return runCommandAndRespond(toolCommand("pdm", env, "sync"))
+	}
+	return installPins(env, target)
+}
+
+func (pdmManager) lock(env pythonEnv) InstallResponseObject {
+	return runCommandAndRespond(toolCommand("pdm", env, "lock"))
+}
+
+func (hatchManager) String() string { return "hatch" }
+
+func (hatchManager) available(env pythonEnv) bool { return onPath("hatch") }
+
+func (hatchManager) skipsPyprojectEdits() bool { return true }
+
+func (hatchManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	return listInstalledPackages(env.interpreter, source)
+}
+
+// hatch has no add / remove, dependencies are declared in pyproject.toml and hatch brings its
+// environment in line with them the next time anything runs in it
+func (hatchManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	if err := addDependency(projectDependencies(), requirement); err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	return runCommandAndRespond(withIndexEnv(exec.Command("hatch", "run", "python", "--version")))
+}
+
+// hatch never uninstalls anything, pruning makes it rebuild its environments without the package
+func (hatchManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	if err := removeDependency(projectDependencies(), pkg); err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	return runCommandAndRespond(exec.Command("hatch", "env", "prune"))
+}
+
+func (hatchManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCommandAndRespond(withIndexEnv(exec.Command("hatch", "run", "python", "-m", "pip", "install", "--upgrade", pkg)))
+}
+
+// hatch environments select extras through features, anything else syncs the default environment
+func (hatchManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	if target.list == nil && target.lock.found() {
+		return installPins(env, target)
+	}
+	if target.list != nil && !target.list.isExtra() && !target.list.isGroup() {
+		return runCommandAndRespond(withIndexEnv(exec.Command("hatch", "run", "python", "--version")))
+	}
+	return installRequirements(env, target)
+}
+
+func (hatchManager) lock(env pythonEnv) InstallResponseObject {
+	return InstallResponseObject{content: "hatch doesn't lock on its own, the hatch-pip-compile plugin adds it", isErr: true}
+}
+
+func (condaManager) String() string { return "conda" }
+
+func (condaManager) available(env pythonEnv) bool { return onPath("conda") }
+
+// conda package names aren't PyPI requirements, they don't belong in pyproject.toml
+func (condaManager) skipsPyprojectEdits() bool { return true }
+
+// the conda environment to act on, the active conda environment when a plain venv is selected
+func condaPrefix(env pythonEnv) (string, error) {
+	if env.kind == envKindConda && env.path != "" {
+		return env.path, nil
+	}
+	if prefix := os.Getenv("CONDA_PREFIX"); prefix != "" {
+		return prefix, nil
+	}
+	return "", fmt.Errorf("%v is not a conda environment", env.name)
+}
+
+func condaCommand(env pythonEnv, args ...string) (*exec.Cmd, error) {
+	var prefix, err = condaPrefix(env)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command("conda", append(args, "-p", prefix)...), nil
+}
+
+func runCondaCommandAndRespond(env pythonEnv, args ...string) InstallResponseObject {
+	var cmd, err = condaCommand(env, args...)
+	if err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	return runCommandAndRespond(cmd)
+}
+
+// conda list knows which channel each package came from, pip installed ones show up as pypi
+func (condaManager) list(env pythonEnv, source string) ([]pythonPackage, error) {
+	if source == packageSourceMetadata {
+		return listMetadataPackages(env.interpreter)
+	}
+	var cmd, err = condaCommand(env, "list", "--json")
+	if err != nil {
+		return nil, err
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Channel string `json:"channel"`
+	}
+	if err := json.Unmarshal(output, &listed); err != nil {
+		return nil, err
+	}
+
+	var pkgs []pythonPackage
+	for _, pkg := range listed {
+		pkgs = append(pkgs, pythonPackage{path: pkg.Name, version: pkg.Version, installer: pkg.Channel, installed: true})
+	}
+	return pkgs, nil
+}
+
+func (condaManager) install(env pythonEnv, requirement string) InstallResponseObject {
+	return runCondaCommandAndRespond(env, "install", "-y", requirement)
+}
+
+func (condaManager) uninstall(env pythonEnv, pkg string) InstallResponseObject {
+	return runCondaCommandAndRespond(env, "remove", "-y", pkg)
+}
+
+func (condaManager) upgrade(env pythonEnv, pkg string) InstallResponseObject {
+	return runCondaCommandAndRespond(env, "update", "-y", pkg)
+}
+
+// environment.yml is conda's manifest, groups and other tools' locks go through pip in the environment
+func (condaManager) sync(env pythonEnv, target syncTarget) InstallResponseObject {
+	switch {
+	case target.list != nil:
+		return installRequirements(env, target)
+	case target.lock.found():
+		return installPins(env, target)
+	}
+	for _, file := range []string{"environment.yml", "environment.yaml"} {
+		if fileExists(file) {
+			return runCondaCommandAndRespond(env, "env", "update", "--prune", "-f", file)
+		}
+	}
+	return InstallResponseObject{content: "no environment.yml to sync to", isErr: true}
+}
+
+// conda's closest thing to a lock is an explicit export of the environment
+func (condaManager) lock(env pythonEnv) InstallResponseObject {
+	var cmd, err = condaCommand(env, "env", "export", "--no-builds")
+	if err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	var path = "environment.lock.yml"
+	if err := os.WriteFile(path, output, 0644); err != nil {
+		return InstallResponseObject{content: err.Error(), isErr: true}
+	}
+	return InstallResponseObject{content: fmt.Sprintf("exported the environment to %v", path)}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// an installed distribution as described by its .dist-info directory
+type distribution struct {
+	name           string
+	version        string
+	summary        string
+	requiresDist   []string
+	requiresPython string
+	license        string
+	licenseFrom    string
+	installer      string
+	editable       bool
+	headers        map[string][]string
+	path           string
+}
+
+// asks the interpreter where it installs packages, purelib and platlib are usually the same
+func findSitePackages(python string) ([]string, error) {
+	var cmd = exec.Command(python, "-c", "import sysconfig; p = sysconfig.get_paths(); print(p['purelib']); print(p['platlib'])")
+	var output, err = cmd.Output()
+	if err != nil {
+		// no interpreter on PATH, a project venv is still worth a look
+		var dirs, _ = filepath.Glob(filepath.Join(".venv", "lib", "python*", "site-packages"))
+		if windowsDir := filepath.Join(".venv", "Lib", "site-packages"); dirExists(windowsDir) {
+			dirs = append(dirs, windowsDir)
+		}
+		if len(dirs) == 0 {
+			return nil, err
+		}
+		return dirs, nil
+	}
+
+	var dirs []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" && dirExists(line) && !containsString(dirs, line) {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
+func readInstalledDistributions(dirs []string) []distribution {
+	var dists []distribution
+	var seen = make(map[string]bool)
+
+	for _, dir := range dirs {
+		var entries, err = os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			var metadataFile string
+			switch {
+			case strings.HasSuffix(entry.Name(), ".dist-info"):
+				metadataFile = "METADATA"
+			case strings.HasSuffix(entry.Name(), ".egg-info") && entry.IsDir():
+				metadataFile = "PKG-INFO"
+			default:
+				continue
+			}
+
+			var dist, ok = readDistribution(filepath.Join(dir, entry.Name()), metadataFile)
+			if !ok || seen[strings.ToLower(dist.name)] {
+				continue
+			}
+			seen[strings.ToLower(dist.name)] = true
+			dists = append(dists, dist)
+		}
+	}
+
+	return dists
+}
+
+func readDistribution(path string, metadataFile string) (distribution, bool) {
+	var dist = distribution{path: path}
+
+	var file, err = os.Open(filepath.Join(path, metadataFile))
+	if err != nil {
+		return dist, false
+	}
+	defer file.Close()
+
+	dist.headers = parseMetadataHeaders(file)
+	dist.name = firstHeader(dist.headers, "Name")
+	dist.version = firstHeader(dist.headers, "Version")
+	dist.summary = firstHeader(dist.headers, "Summary")
+	dist.requiresPython = firstHeader(dist.headers, "Requires-Python")
+	dist.requiresDist = dist.headers["Requires-Dist"]
+	dist.license, dist.licenseFrom = distributionLicense(dist.headers)
+	if dist.name == "" {
+		return dist, false
+	}
+
+	if installer, err := os.ReadFile(filepath.Join(path, "INSTALLER")); err == nil {
+		dist.installer = strings.TrimSpace(string(installer))
+	}
+
+	// PEP 610, editable installs record dir_info.editable
+	if data, err := os.ReadFile(filepath.Join(path, "direct_url.json")); err == nil {
+		var directURL struct {
+			DirInfo struct {
+				Editable bool `json:"editable"`
+			} `json:"dir_info"`
+		}
+		if json.Unmarshal(data, &directURL) == nil {
+			dist.editable = directURL.DirInfo.Editable
+		}
+	}
+
+	return dist, true
+}
+
+// core metadata is an email style header block, the body (long description) is ignored
+func parseMetadataHeaders(r io.Reader) map[string][]string {
+	var headers = make(map[string][]string)
+	var scanner = bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var last string
+	for scanner.Scan() {
+		// whitespace only lines are continuations (multi line License fields), only an empty line ends the headers
+		var line = strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			break
+		}
+
+		if (line[0] == ' ' || line[0] == '\t') && last != "" {
+			var values = headers[last]
+			values[len(values)-1] += "\n" + strings.TrimSpace(line)
+			continue
+		}
+
+		var key, value, found = strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		last = strings.TrimSpace(key)
+		headers[last] = append(headers[last], strings.TrimSpace(value))
+	}
+
+	return headers
+}
+
+func firstHeader(headers map[string][]string, key string) string {
+	if values := headers[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func listMetadataPackages(python string) ([]pythonPackage, error) {
+	var dirs, err = findSitePackages(python)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkgs []pythonPackage
+	for _, dist := range readInstalledDistributions(dirs) {
+		pkgs = append(pkgs, pythonPackage{
+			path:      dist.name,
+			version:   dist.version,
+			summary:   dist.summary,
+			requires:  dist.requiresDist,
+			license:   dist.license,
+			installer: dist.installer,
+			editable:  dist.editable,
+			installed: true,
+		})
+	}
+	return pkgs, nil
+}
+
+func dirExists(path string) bool {
+	var info, err = os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	var info, err = os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"lazypython/pep"
+)
+
+// every PyPI advisory OSV knows about, PYSEC (the PyPA database) and GHSA alike
+const osvDatabaseURL = "https://osv-vulnerabilities.storage.googleapis.com/PyPI/all.zip"
+const osvDatabaseFileName = "osv_pypi_advisories.zip"
+const osvDatabaseMaxAge = 24 * time.Hour
+
+// the zip is tens of megabytes, a slow connection gets a while to finish it but not forever
+const osvDownloadTimeout = 10 * time.Minute
+
+type osvEvent struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+type osvAdvisory struct {
+	ID        string   `json:"id"`
+	Aliases   []string `json:"aliases"`
+	Summary   string   `json:"summary"`
+	Withdrawn string   `json:"withdrawn"`
+	Severity  []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string     `json:"type"`
+			Events []osvEvent `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// one advisory that applies to an installed package
+type auditFinding struct {
+	pkg      string
+	version  string
+	ids      []string
+	summary  string
+	severity string
+	affected string
+	fixed    string
+}
+
+// CVE ids when the advisory has any, its own id otherwise
+func (f auditFinding) cves() string {
+	var cves []string
+	for _, id := range f.ids {
+		if strings.HasPrefix(id, "CVE-") {
+			cves = append(cves, id)
+		}
+	}
+	if len(cves) == 0 {
+		return f.ids[0]
+	}
+	return strings.Join(cves, ", ")
+}
+
+// an affected interval, an empty lower bound is every version before upper
+type osvInterval struct {
+	introduced   string
+	upper        string
+	lastAffected bool
+}
+
+func (r osvInterval) contains(v pep.Version) bool {
+	if r.introduced != "" && compareVersions(v.String(), r.introduced) < 0 {
+		return false
+	}
+	if r.upper == "" {
+		return true
+	}
+	var cmp = compareVersions(v.String(), r.upper)
+	return cmp < 0 || (r.lastAffected && cmp == 0)
+}
+
+func (r osvInterval) String() string {
+	var parts []string
+	if r.introduced != "" {
+		parts = append(parts, ">="+r.introduced)
+	}
+	switch {
+	case r.upper != "" && r.lastAffected:
+		parts = append(parts, "<="+r.upper)
+	case r.upper != "":
+		parts = append(parts, "<"+r.upper)
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, ",")
+}
+
+// OSV events only make sense sorted, "0" introduces everything
+func osvIntervals(events []osvEvent) []osvInterval {
+	var version = func(e osvEvent) string {
+		return e.Introduced + e.Fixed + e.LastAffected
+	}
+	events = append([]osvEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Introduced == "0" || events[j].Introduced == "0" {
+			return events[i].Introduced == "0" && events[j].Introduced != "0"
+		}
+		return compareVersions(version(events[i]), version(events[j])) < 0
+	})
+
+	var intervals []osvInterval
+	var open *osvInterval
+	for _, event := range events {
+		switch {
+		case event.Introduced != "":
+			if open == nil {
+				open = &osvInterval{}
+				if event.Introduced != "0" {
+					open.introduced = event.Introduced
+				}
+			}
+		case open != nil && event.Fixed != "":
+			open.upper = event.Fixed
+			intervals = append(intervals, *open)
+			open = nil
+		case open != nil && event.LastAffected != "":
+			open.upper = event.LastAffected
+			open.lastAffected = true
+			intervals = append(intervals, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		intervals = append(intervals, *open)
+	}
+	return intervals
+}
+
+// the finding for version when the advisory covers it, ranges are checked before the explicit versions list
+func (a osvAdvisory) match(name string, version pep.Version) (auditFinding, bool) {
+	if a.Withdrawn != "" {
+		return auditFinding{}, false
+	}
+	for _, affected := range a.Affected {
+		if affected.Package.Ecosystem != "PyPI" || pep.Normalize(affected.Package.Name) != name {
+			continue
+		}
+
+		var finding = auditFinding{ids: append([]string{a.ID}, a.Aliases...), summary: a.Summary, severity: a.severity()}
+		for _, r := range affected.Ranges {
+			if r.Type != "ECOSYSTEM" {
+				continue
+			}
+			for _, interval := range osvIntervals(r.Events) {
+				if !interval.contains(version) {
+					continue
+				}
+				finding.affected = interval.String()
+				if !interval.lastAffected {
+					finding.fixed = interval.upper
+				}
+				return finding, true
+			}
+		}
+
+		for _, listed := range affected.Versions {
+			if compareVersions(listed, version.String()) == 0 {
+				finding.affected = "=" + listed
+				return finding, true
+			}
+		}
+	}
+	return auditFinding{}, false
+}
+
+// GHSA entries carry a rating, everything else only has a CVSS vector to score
+func (a osvAdvisory) severity() string {
+	if a.DatabaseSpecific.Severity != "" {
+		return strings.ToUpper(a.DatabaseSpecific.Severity)
+	}
+	for _, severity := range a.Severity {
+		if severity.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := cvss3BaseScore(severity.Score); ok {
+			return fmt.Sprintf("%v %.1f", cvssRating(score), score)
+		}
+	}
+	return "-"
+}
+
+// the CVSS v3.x base score of a vector like "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+func cvss3BaseScore(vector string) (float64, bool) {
+	var metrics = make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			metrics[key] = value
+		}
+	}
+
+	var changed = metrics["S"] == "C"
+	var weights = map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	if changed {
+		weights["PR"]["L"] = 0.68
+		weights["PR"]["H"] = 0.5
+	}
+	var w = make(map[string]float64)
+	for key, values := range weights {
+		var value, ok = values[metrics[key]]
+		if !ok {
+			return 0, false
+		}
+		w[key] = value
+	}
+
+	var iss = 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	var impact = 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	var exploitability = 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	var score = impact + exploitability
+	if changed {
+		score *= 1.08
+	}
+	return math.Ceil(math.Min(score, 10)*10) / 10, true
+}
+
+func cvssRating(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MODERATE"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+func severityRank(severity string) int {
+	var rating, _, _ = strings.Cut(severity, " ")
+	switch rating {
+	case "CRITICAL":
+		return 4
+	case "HIGH":
+		return 3
+	case "MODERATE", "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	}
+	return 0
+}
+
+func osvDatabasePath() (string, error) {
+	return getCachePath(osvDatabaseFileName)
+}
+
+// when the advisory database was last downloaded or imported, zero when there isn't one
+func osvDatabaseUpdated() time.Time {
+	var path, err = osvDatabasePath()
+	if err != nil {
+		return time.Time{}
+	}
+	var info, statErr = os.Stat(path)
+	if statErr != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// downloads the database next to the package cache, the old copy is only replaced once the new one is complete
+func downloadOSVDatabase(ctx context.Context) error {
+	var path, err = osvDatabasePath()
+	if err != nil {
+		return err
+	}
+
+	var req, reqErr = http.NewRequestWithContext(ctx, http.MethodGet, osvDatabaseURL, nil)
+	if reqErr != nil {
+		return reqErr
+	}
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = indexHeaderTimeout
+	var client = &http.Client{Transport: transport, Timeout: osvDownloadTimeout}
+	var resp, getErr = client.Do(req)
+	if getErr != nil {
+		return getErr
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("advisory database: %v", resp.Status)
+	}
+
+	var partial = path + ".part"
+	var file, createErr = os.Create(partial)
+	if createErr != nil {
+		return createErr
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(partial)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(partial, path)
+}
+
+// copies an OSV zip from disk in as the advisory database, for machines that never go online
+func importOSVDatabase(source string) error {
+	var reader, err = zip.OpenReader(source)
+	if err != nil {
+		return fmt.Errorf("%v is not an OSV zip: %w", source, err)
+	}
+	reader.Close()
+
+	var data, readErr = os.ReadFile(source)
+	if readErr != nil {
+		return readErr
+	}
+	var path, pathErr = osvDatabasePath()
+	if pathErr != nil {
+		return pathErr
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// downloads a fresh database when the local one is missing or old, a failed download falls back to the local copy.
+// A canceled download returns the context's error, there's nobody left to show the audit to
+func refreshOSVDatabase(ctx context.Context, force bool) (time.Time, error) {
+	var updated = osvDatabaseUpdated()
+	if !force && !updated.IsZero() && time.Since(updated) < osvDatabaseMaxAge {
+		return updated, nil
+	}
+	var err = downloadOSVDatabase(ctx)
+	if ctx.Err() != nil {
+		return time.Time{}, ctx.Err()
+	}
+	if err != nil && updated.IsZero() {
+		return updated, fmt.Errorf("no advisory database and the download failed: %w", err)
+	}
+	if err != nil {
+		return updated, fmt.Errorf("offline, using advisories from %v: %w", updated.Format("2006-01-02"), err)
+	}
+	return osvDatabaseUpdated(), nil
+}
+
+// reads every advisory in the zip that mentions one of the named (normalized) packages
+func loadAdvisories(names map[string]bool) (map[string][]osvAdvisory, error) {
+	var path, err = osvDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	var reader, openErr = zip.OpenReader(path)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer reader.Close()
+
+	var advisories = make(map[string][]osvAdvisory)
+	for _, file := range reader.File {
+		if !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+		var content, err = file.Open()
+		if err != nil {
+			return nil, err
+		}
+		var advisory osvAdvisory
+		var decodeErr = json.NewDecoder(content).Decode(&advisory)
+		content.Close()
+		if decodeErr != nil {
+			return nil, fmt.Errorf("%v: %w", filepath.Base(file.Name), decodeErr)
+		}
+
+		var seen = make(map[string]bool)
+		for _, affected := range advisory.Affected {
+			var name = pep.Normalize(affected.Package.Name)
+			if names[name] && !seen[name] {
+				seen[name] = true
+				advisories[name] = append(advisories[name], advisory)
+			}
+		}
+	}
+	return advisories, nil
+}
+
+// one finding per vulnerability, OSV ships the PYSEC and GHSA copies of the same issue as separate entries
+func mergeFindings(findings []auditFinding) []auditFinding {
+	var merged []auditFinding
+	for _, finding := range findings {
+		var duplicate = -1
+		for i, existing := range merged {
+			if sharesID(existing.ids, finding.ids) {
+				duplicate = i
+				break
+			}
+		}
+		if duplicate < 0 {
+			merged = append(merged, finding)
+			continue
+		}
+
+		var existing = &merged[duplicate]
+		for _, id := range finding.ids {
+			if !sharesID(existing.ids, []string{id}) {
+				existing.ids = append(existing.ids, id)
+			}
+		}
+		if severityRank(finding.severity) > severityRank(existing.severity) {
+			existing.severity = finding.severity
+		}
+		if existing.summary == "" {
+			existing.summary = finding.summary
+		}
+		if existing.fixed == "" || (finding.fixed != "" && compareVersions(finding.fixed, existing.fixed) > 0) {
+			existing.fixed = finding.fixed
+			existing.affected = finding.affected
+		}
+	}
+	return merged
+}
+
+func sharesID(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// every installed package checked against the local advisory database, worst first
+func auditPackages(pkgs []pythonPackage) ([]auditFinding, error) {
+	var versions = make(map[string]pep.Version)
+	var names = make(map[string]bool)
+	var display = make(map[string]string)
+	for _, pkg := range pkgs {
+		var version, err = pep.ParseVersion(pkg.version)
+		if !pkg.installed || err != nil {
+			continue
+		}
+		var name = pep.Normalize(pkg.path)
+		versions[name] = version
+		names[name] = true
+		display[name] = pkg.path
+	}
+
+	var advisories, err = loadAdvisories(names)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []auditFinding
+	for name, list := range advisories {
+		var matched []auditFinding
+		for _, advisory := range list {
+			if finding, ok := advisory.match(name, versions[name]); ok {
+				finding.pkg = display[name]
+				finding.version = versions[name].String()
+				matched = append(matched, finding)
+			}
+		}
+		findings = append(findings, mergeFindings(matched)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if a, b := severityRank(findings[i].severity), severityRank(findings[j].severity); a != b {
+			return a > b
+		}
+		if findings[i].pkg != findings[j].pkg {
+			return strings.ToLower(findings[i].pkg) < strings.ToLower(findings[j].pkg)
+		}
+		return findings[i].ids[0] < findings[j].ids[0]
+	})
+	return findings, nil
+}
+
+// the lowest version that fixes every advisory against the package, empty when one of them has no fix yet
+func fixedVersionFor(findings []auditFinding, pkg string) string {
+	var fixed string
+	for _, finding := range findings {
+		if finding.pkg != pkg {
+			continue
+		}
+		if finding.fixed == "" {
+			return ""
+		}
+		if fixed == "" || compareVersions(finding.fixed, fixed) > 0 {
+			fixed = finding.fixed
+		}
+	}
+	return fixed
+}
@@ -38,36 +38,51 @@ func drawPackageInstallScreen(m *model) string {
 		Height(tableHeight).
 		Render(m.remotePackageTable.View())
 
+	var packageInfo = fmt.Sprintf(
+		"Package name: %v\nPackage version: %v\n\nAuthor email: %v\n\nSummary: %v\n\nSize: %v bytes\n\nDownloads (Last Week): %v\n\nDownloads (Last Month): %v",
+		m.remotePackageSelected.Info.Name,
+		m.remotePackageSelected.Info.Version,
+		m.remotePackageSelected.Info.AuthorEmail,
+		m.remotePackageSelected.Info.Summary,
+		func() string {
+			if files, ok := m.remotePackageSelected.Releases[m.remotePackageSelected.Info.Version]; ok && len(files) > 0 {
+				return strconv.Itoa(files[0].Size)
+			}
+			return "Unknown"
+		}(),
+		m.remotePackageSelected.Downloads.LastWeek,
+		m.remotePackageSelected.Downloads.LastMonth,
+	)
+	switch {
+	case m.packageInfoLoading != "":
+		packageInfo = fmt.Sprintf("%v Loading %v...", m.spinner.View(), m.packageInfoLoading)
+	case m.packageInfoErr != nil:
+		packageInfo = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render("Couldn't load the package\n\n" + m.packageInfoErr.Error())
+	}
+	if m.openVersionPicker {
+		packageInfo = "Releases of " + m.remotePackageSelected.Info.Name + " (Enter to pick, Esc to close)\n\n" + m.versionTable.View()
+	}
+
 	var packageInfoBox = lipgloss.NewStyle().
 		Width(m.window.width/2-10).
 		Height((m.window.height/2)+1).
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("63")).
 		Padding(0, 1).
-		Render(
-			fmt.Sprintf(
-				"Package name: %v\nPackage version: %v\n\nAuthor email: %v\n\nSummary: %v\n\nSize: %v bytes\n\nDownloads (Last Week): %v\n\nDownloads (Last Month): %v",
-				m.remotePackageSelected.Info.Name,
-				m.remotePackageSelected.Info.Version,
-				m.remotePackageSelected.Info.AuthorEmail,
-				m.remotePackageSelected.Info.Summary,
-				func() string {
-					if files, ok := m.remotePackageSelected.Releases[m.remotePackageSelected.Info.Version]; ok && len(files) > 0 {
-						return strconv.Itoa(files[0].Size)
-					}
-					return "Unknown"
-				}(),
-				m.remotePackageSelected.Downloads.LastWeek,
-				m.remotePackageSelected.Downloads.LastMonth,
-			))
+		Render(packageInfo)
 
 	var jointBox = lipgloss.JoinHorizontal(lipgloss.Center, tableBox, packageInfoBox)
+
+	var target string
+	if len(m.remotePackageTable.SelectedRow()) > 0 {
+		target = " " + installRequirement(m)
+	}
 	var footer = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
 		BorderForeground(lipgloss.Color("63")).
 		Padding(0, 1).
 		Width(m.window.width - 8).
-		Render(fmt.Sprintf("Type to filter | Ctrl+A to install | Esc to cancel * %s", lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render(m.info)))
+		Render(fmt.Sprintf("Type to filter | Ctrl+R versions | Ctrl+A to install%v | Ctrl+T as tool | Ctrl+O run once | Esc to cancel * %s", target, lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render(m.info)))
 
 	screen := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -0,0 +1,352 @@
+package pep
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Environment holds the marker variables a requirement is evaluated against,
+// keyed by their PEP 508 names (python_version, sys_platform, extra, ...).
+type Environment map[string]string
+
+// NewEnvironment describes the current machine for an interpreter with the
+// given full version (e.g. "3.12.1"). Variables Go can't know are left empty.
+func NewEnvironment(pythonFullVersion string) Environment {
+	var env = Environment{
+		"python_full_version":            pythonFullVersion,
+		"implementation_name":            "cpython",
+		"implementation_version":         pythonFullVersion,
+		"platform_python_implementation": "CPython",
+		"extra":                          "",
+	}
+
+	if v, err := ParseVersion(pythonFullVersion); err == nil && len(v.Release) >= 2 {
+		env["python_version"] = fmt.Sprintf("%d.%d", v.Release[0], v.Release[1])
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		env["os_name"], env["sys_platform"], env["platform_system"] = "nt", "win32", "Windows"
+	case "darwin":
+		env["os_name"], env["sys_platform"], env["platform_system"] = "posix", "darwin", "Darwin"
+	default:
+		env["os_name"], env["sys_platform"], env["platform_system"] = "posix", runtime.GOOS, strings.ToUpper(runtime.GOOS[:1])+runtime.GOOS[1:]
+	}
+
+	switch runtime.GOARCH {
+	case "amd64":
+		env["platform_machine"] = "x86_64"
+		if runtime.GOOS == "windows" {
+			env["platform_machine"] = "AMD64"
+		}
+	case "arm64":
+		env["platform_machine"] = "aarch64"
+		if runtime.GOOS == "darwin" {
+			env["platform_machine"] = "arm64"
+		}
+	case "386":
+		env["platform_machine"] = "i686"
+	default:
+		env["platform_machine"] = runtime.GOARCH
+	}
+
+	return env
+}
+
+// WithExtra returns a copy of env with the extra variable set.
+func (env Environment) WithExtra(extra string) Environment {
+	var copied = make(Environment, len(env))
+	for k, v := range env {
+		copied[k] = v
+	}
+	copied["extra"] = extra
+	return copied
+}
+
+// Marker is a parsed environment marker expression.
+type Marker struct {
+	expr markerNode
+}
+
+type markerNode interface {
+	eval(env Environment) bool
+	String() string
+}
+
+type markerValue struct {
+	variable bool
+	value    string
+}
+
+type markerCompare struct {
+	lhs markerValue
+	op  string
+	rhs markerValue
+}
+
+type markerBool struct {
+	op    string
+	left  markerNode
+	right markerNode
+}
+
+type markerGroup struct {
+	inner markerNode
+}
+
+var markerVariables = map[string]bool{
+	"python_version": true, "python_full_version": true, "os_name": true, "sys_platform": true,
+	"platform_release": true, "platform_system": true, "platform_version": true, "platform_machine": true,
+	"platform_python_implementation": true, "implementation_name": true, "implementation_version": true,
+	"extra": true, "extras": true, "dependency_groups": true,
+	// pre PEP 508 spellings that still show up in old metadata
+	"os.name": true, "sys.platform": true, "platform.version": true, "platform.machine": true,
+	"platform.python_implementation": true, "python_implementation": true,
+}
+
+var markerAliases = map[string]string{
+	"os.name": "os_name", "sys.platform": "sys_platform", "platform.version": "platform_version",
+	"platform.machine": "platform_machine", "platform.python_implementation": "platform_python_implementation",
+	"python_implementation": "platform_python_implementation",
+}
+
+// ParseMarker parses the part of a requirement after the ';'.
+func ParseMarker(s string) (*Marker, error) {
+	var p = markerParser{tokens: tokenizeMarker(s), source: s}
+	var expr, err = p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in marker %q", p.tokens[p.pos], s)
+	}
+	return &Marker{expr: expr}, nil
+}
+
+// Evaluate reports whether the marker holds in env.
+func (m *Marker) Evaluate(env Environment) bool {
+	return m.expr.eval(env)
+}
+
+// String returns the marker in a normalized form.
+func (m *Marker) String() string {
+	return m.expr.String()
+}
+
+func (v markerValue) resolve(env Environment) string {
+	if v.variable {
+		if alias, ok := markerAliases[v.value]; ok {
+			return env[alias]
+		}
+		return env[v.value]
+	}
+	return v.value
+}
+
+func (v markerValue) String() string {
+	if v.variable {
+		return v.value
+	}
+	return `"` + v.value + `"`
+}
+
+func (c markerCompare) eval(env Environment) bool {
+	var lhs = c.lhs.resolve(env)
+	var rhs = c.rhs.resolve(env)
+
+	// extra names are compared normalized on both sides
+	if (c.lhs.variable && c.lhs.value == "extra") || (c.rhs.variable && c.rhs.value == "extra") {
+		lhs, rhs = Normalize(lhs), Normalize(rhs)
+	}
+
+	switch c.op {
+	case "in":
+		return strings.Contains(rhs, lhs)
+	case "not in":
+		return !strings.Contains(rhs, lhs)
+	}
+
+	if spec, err := ParseSpecifier(c.op + rhs); err == nil {
+		if v, err := ParseVersion(lhs); err == nil {
+			return spec.Contains(v)
+		}
+	}
+
+	switch c.op {
+	case "==", "===":
+		return lhs == rhs
+	case "!=":
+		return lhs != rhs
+	case "<":
+		return lhs < rhs
+	case "<=":
+		return lhs <= rhs
+	case ">":
+		return lhs > rhs
+	case ">=":
+		return lhs >= rhs
+	}
+	return false
+}
+
+func (c markerCompare) String() string {
+	return c.lhs.String() + " " + c.op + " " + c.rhs.String()
+}
+
+func (b markerBool) eval(env Environment) bool {
+	if b.op == "and" {
+		return b.left.eval(env) && b.right.eval(env)
+	}
+	return b.left.eval(env) || b.right.eval(env)
+}
+
+func (b markerBool) String() string {
+	return b.left.String() + " " + b.op + " " + b.right.String()
+}
+
+func (g markerGroup) eval(env Environment) bool {
+	return g.inner.eval(env)
+}
+
+func (g markerGroup) String() string {
+	return "(" + g.inner.String() + ")"
+}
+
+type markerParser struct {
+	tokens []string
+	pos    int
+	source string
+}
+
+func (p *markerParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *markerParser) next() string {
+	var token = p.peek()
+	p.pos++
+	return token
+}
+
+func (p *markerParser) parseOr() (markerNode, error) {
+	var left, err = p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.next()
+		var right, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = markerBool{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *markerParser) parseAnd() (markerNode, error) {
+	var left, err = p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.next()
+		var right, err = p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		left = markerBool{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *markerParser) parseExpr() (markerNode, error) {
+	if p.peek() == "(" {
+		p.next()
+		var inner, err = p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ')' in marker %q", p.source)
+		}
+		return markerGroup{inner: inner}, nil
+	}
+
+	var lhs, err = p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	var op = p.next()
+	switch op {
+	case "<", "<=", "==", "!=", ">=", ">", "~=", "===", "in":
+	case "not":
+		if p.next() != "in" {
+			return nil, fmt.Errorf("expected 'not in' in marker %q", p.source)
+		}
+		op = "not in"
+	default:
+		return nil, fmt.Errorf("unexpected %q in marker %q", op, p.source)
+	}
+
+	rhs, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return markerCompare{lhs: lhs, op: op, rhs: rhs}, nil
+}
+
+func (p *markerParser) parseValue() (markerValue, error) {
+	var token = p.next()
+	switch {
+	case len(token) >= 2 && (token[0] == '"' || token[0] == '\'') && token[len(token)-1] == token[0]:
+		return markerValue{value: token[1 : len(token)-1]}, nil
+	case markerVariables[token]:
+		return markerValue{variable: true, value: token}, nil
+	case token == "":
+		return markerValue{}, fmt.Errorf("unexpected end of marker %q", p.source)
+	}
+	return markerValue{}, fmt.Errorf("unknown marker variable %q in %q", token, p.source)
+}
+
+func tokenizeMarker(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		var c = s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"' || c == '\'':
+			var end = strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				tokens = append(tokens, s[i:])
+				return tokens
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case strings.ContainsRune("<>=!~", rune(c)):
+			var j = i
+			for j < len(s) && strings.ContainsRune("<>=!~", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			var j = i
+			for j < len(s) && !strings.ContainsRune(" \t()<>=!~\"'", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,89 @@
+package pep
+
+import "testing"
+
+var linuxEnvironment = Environment{
+	"python_version":                 "3.10",
+	"python_full_version":            "3.10.4",
+	"os_name":                        "posix",
+	"sys_platform":                   "linux",
+	"platform_system":                "Linux",
+	"platform_machine":               "x86_64",
+	"platform_release":               "6.1.0-18-amd64",
+	"platform_python_implementation": "CPython",
+	"implementation_name":            "cpython",
+	"extra":                          "",
+}
+
+func TestMarkerEvaluate(t *testing.T) {
+	var cases = []struct {
+		marker string
+		extra  string
+		want   bool
+	}{
+		// versions compare as versions, not strings
+		{`python_version >= "3.8"`, "", true},
+		{`python_version < "3.9"`, "", false},
+		{`python_version > "3.9"`, "", true},
+		{`python_full_version < "3.10.10"`, "", true},
+		{`python_version ~= "3.8"`, "", true},
+		{`python_version == "3.10.*"`, "", true},
+		{`python_version != "3.10"`, "", false},
+		{`"3.11" > python_version`, "", true},
+
+		// anything else compares as a string
+		{`sys_platform == "linux"`, "", true},
+		{`sys_platform == 'win32'`, "", false},
+		{`platform_release >= "6"`, "", true},
+		{`os.name == "posix"`, "", true},
+		{`platform_machine in "x86_64 aarch64"`, "", true},
+		{`platform_machine not in "x86_64 aarch64"`, "", false},
+		{`"linux" in sys_platform`, "", true},
+		{`"win" not in sys_platform`, "", true},
+
+		// extras are normalized on both sides
+		{`extra == "socks"`, "socks", true},
+		{`extra == "socks"`, "", false},
+		{`extra == "Fast_JSON"`, "fast-json", true},
+		{`extra == "fast-json"`, "Fast.JSON", true},
+		{`extra != "socks"`, "", true},
+		{`"socks" in extra`, "socks", true},
+
+		// precedence and grouping
+		{`python_version < "3.8" or sys_platform == "linux"`, "", true},
+		{`python_version < "3.8" and sys_platform == "linux"`, "", false},
+		{`sys_platform == "win32" or python_version < "3.8" and extra == "x"`, "", false},
+		{`sys_platform == "linux" or python_version < "3.8" and extra == "x"`, "", true},
+		{`(sys_platform == "linux" or python_version < "3.8") and extra == "x"`, "", false},
+		{`(sys_platform == "linux" or python_version < "3.8") and extra == "x"`, "x", true},
+		{`sys_platform=="linux"and(python_version>="3")`, "", true},
+	}
+	for _, c := range cases {
+		var marker, err = ParseMarker(c.marker)
+		if err != nil {
+			t.Errorf("ParseMarker(%q): %v", c.marker, err)
+			continue
+		}
+		if got := marker.Evaluate(linuxEnvironment.WithExtra(c.extra)); got != c.want {
+			t.Errorf("%v with extra %q = %v, want %v", c.marker, c.extra, got, c.want)
+		}
+	}
+}
+
+func TestParseMarkerInvalid(t *testing.T) {
+	for _, input := range []string{
+		``,
+		`python_version`,
+		`python_version >= `,
+		`python_version is "3.8"`,
+		`python_version not "3.8"`,
+		`pyversion >= "3.8"`,
+		`(python_version >= "3.8"`,
+		`python_version >= "3.8")`,
+		`python_version >= "3.8" and`,
+	} {
+		if _, err := ParseMarker(input); err == nil {
+			t.Errorf("ParseMarker(%q) should fail", input)
+		}
+	}
+}
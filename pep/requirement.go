@@ -0,0 +1,121 @@
+package pep
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var requirementNamePattern = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?`)
+
+// Requirement is a parsed PEP 508 dependency specification.
+type Requirement struct {
+	Name      string
+	Extras    []string
+	Specifier SpecifierSet
+	URL       string
+	Marker    *Marker
+}
+
+// ParseRequirement parses strings like `requests[socks]>=2,<3; python_version<"3.11"`.
+func ParseRequirement(s string) (Requirement, error) {
+	var req Requirement
+	var rest = strings.TrimSpace(s)
+
+	// comments are allowed after a requirement in most files we read
+	if i := strings.Index(rest, " #"); i >= 0 {
+		rest = strings.TrimSpace(rest[:i])
+	}
+
+	req.Name = requirementNamePattern.FindString(rest)
+	if req.Name == "" {
+		return req, fmt.Errorf("invalid requirement: %q", s)
+	}
+	rest = strings.TrimSpace(rest[len(req.Name):])
+
+	if strings.HasPrefix(rest, "[") {
+		var end = strings.Index(rest, "]")
+		if end < 0 {
+			return req, fmt.Errorf("unterminated extras in %q", s)
+		}
+		for _, extra := range strings.Split(rest[1:end], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				req.Extras = append(req.Extras, Normalize(extra))
+			}
+		}
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	var marker string
+	if strings.HasPrefix(rest, "@") {
+		// a url can contain ';', the marker has to be separated by whitespace
+		rest = strings.TrimSpace(rest[1:])
+		if i := strings.Index(rest, " ;"); i >= 0 {
+			marker = rest[i+2:]
+			rest = rest[:i]
+		}
+		req.URL = strings.TrimSpace(rest)
+		if req.URL == "" {
+			return req, fmt.Errorf("missing url in %q", s)
+		}
+	} else {
+		var spec = rest
+		if i := strings.Index(rest, ";"); i >= 0 {
+			spec = rest[:i]
+			marker = rest[i+1:]
+		}
+
+		spec = strings.TrimSpace(spec)
+		if strings.HasPrefix(spec, "(") && strings.HasSuffix(spec, ")") {
+			spec = spec[1 : len(spec)-1]
+		}
+
+		var set, err = ParseSpecifierSet(spec)
+		if err != nil {
+			return req, err
+		}
+		req.Specifier = set
+	}
+
+	if strings.TrimSpace(marker) != "" {
+		var m, err = ParseMarker(marker)
+		if err != nil {
+			return req, err
+		}
+		req.Marker = m
+	}
+
+	return req, nil
+}
+
+// NormalizedName returns the PEP 503 form of the project name.
+func (r Requirement) NormalizedName() string {
+	return Normalize(r.Name)
+}
+
+// Applies reports whether the requirement's marker holds in env. Requirements
+// without a marker always apply.
+func (r Requirement) Applies(env Environment) bool {
+	return r.Marker == nil || r.Marker.Evaluate(env)
+}
+
+// String rebuilds the requirement in its canonical form.
+func (r Requirement) String() string {
+	var b strings.Builder
+	b.WriteString(r.Name)
+	if len(r.Extras) > 0 {
+		b.WriteString("[" + strings.Join(r.Extras, ",") + "]")
+	}
+	if r.URL != "" {
+		b.WriteString(" @ " + r.URL)
+		if r.Marker != nil {
+			b.WriteString(" ")
+		}
+	} else {
+		b.WriteString(r.Specifier.String())
+	}
+	if r.Marker != nil {
+		b.WriteString("; " + r.Marker.String())
+	}
+	return b.String()
+}
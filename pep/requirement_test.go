@@ -0,0 +1,53 @@
+package pep
+
+import "testing"
+
+func TestParseRequirement(t *testing.T) {
+	var cases = []struct {
+		input string
+		want  string
+		name  string
+	}{
+		{"requests", "requests", "requests"},
+		{"Requests[Socks, security] >= 2.8.1, ==2.8.*", "Requests[socks,security]>=2.8.1,==2.8.*", "requests"},
+		{"zope.interface (>=5)", "zope.interface>=5", "zope-interface"},
+		{`tomli; python_version < "3.11"`, `tomli; python_version < "3.11"`, "tomli"},
+		{"pip @ https://github.com/pypa/pip/archive/1.3.1.zip#sha1=da9234ee ; extra == 'dev'", `pip @ https://github.com/pypa/pip/archive/1.3.1.zip#sha1=da9234ee ; extra == "dev"`, "pip"},
+		{"black>=24  # formatter", "black>=24", "black"},
+	}
+	for _, c := range cases {
+		var req, err = ParseRequirement(c.input)
+		if err != nil {
+			t.Errorf("ParseRequirement(%q): %v", c.input, err)
+			continue
+		}
+		if got := req.String(); got != c.want {
+			t.Errorf("ParseRequirement(%q) = %v, want %v", c.input, got, c.want)
+		}
+		if got := req.NormalizedName(); got != c.name {
+			t.Errorf("ParseRequirement(%q) name = %v, want %v", c.input, got, c.name)
+		}
+	}
+
+	for _, input := range []string{"", ">=1.0", "requests[socks", "requests>=", "pkg @ ", `requests; python_version >>`} {
+		if _, err := ParseRequirement(input); err == nil {
+			t.Errorf("ParseRequirement(%q) should fail", input)
+		}
+	}
+}
+
+func TestRequirementApplies(t *testing.T) {
+	var req, _ = ParseRequirement(`pysocks>=1.5.6; extra == "socks" and python_version >= "3.8"`)
+	if req.Applies(linuxEnvironment) {
+		t.Error("applies without the extra")
+	}
+	if !req.Applies(linuxEnvironment.WithExtra("socks")) {
+		t.Error("doesn't apply with the extra")
+	}
+	if plain, _ := ParseRequirement("requests"); !plain.Applies(linuxEnvironment) {
+		t.Error("a requirement without a marker should always apply")
+	}
+	if got := NewEnvironment("3.12.1")["python_version"]; got != "3.12" {
+		t.Errorf("python_version = %q, want 3.12", got)
+	}
+}
@@ -0,0 +1,201 @@
+package pep
+
+import (
+	"fmt"
+	"strings"
+)
+
+var specifierOperators = []string{"===", "~=", "==", "!=", "<=", ">=", "<", ">"}
+
+// Specifier is a single PEP 440 version clause such as ">=2.0" or "==1.4.*".
+type Specifier struct {
+	Operator string
+	Version  string
+	Wildcard bool
+	version  Version
+}
+
+// SpecifierSet is a comma separated list of specifiers that must all match.
+type SpecifierSet []Specifier
+
+// ParseSpecifier parses a single clause.
+func ParseSpecifier(s string) (Specifier, error) {
+	s = strings.TrimSpace(s)
+
+	var spec Specifier
+	for _, op := range specifierOperators {
+		if strings.HasPrefix(s, op) {
+			spec.Operator = op
+			break
+		}
+	}
+	if spec.Operator == "" {
+		return spec, fmt.Errorf("invalid specifier: %q", s)
+	}
+
+	spec.Version = strings.TrimSpace(s[len(spec.Operator):])
+	if spec.Version == "" {
+		return spec, fmt.Errorf("invalid specifier: %q", s)
+	}
+
+	// arbitrary equality is a plain string match, nothing to parse
+	if spec.Operator == "===" {
+		return spec, nil
+	}
+
+	var raw = spec.Version
+	if strings.HasSuffix(raw, ".*") {
+		if spec.Operator != "==" && spec.Operator != "!=" {
+			return spec, fmt.Errorf("wildcards are only allowed with == and !=: %q", s)
+		}
+		spec.Wildcard = true
+		raw = strings.TrimSuffix(raw, ".*")
+	}
+
+	var v, err = ParseVersion(raw)
+	if err != nil {
+		return spec, fmt.Errorf("invalid specifier: %q", s)
+	}
+	if spec.Operator == "~=" && len(v.Release) < 2 {
+		return spec, fmt.Errorf("~= needs at least two release segments: %q", s)
+	}
+	spec.version = v
+
+	return spec, nil
+}
+
+// ParseSpecifierSet parses a comma separated specifier list, an empty string
+// gives an empty set that matches everything.
+func ParseSpecifierSet(s string) (SpecifierSet, error) {
+	var set SpecifierSet
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		var spec, err = ParseSpecifier(part)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, spec)
+	}
+	return set, nil
+}
+
+// String returns the specifier as written, without spaces.
+func (s Specifier) String() string {
+	var version = s.Version
+	if s.Wildcard && !strings.HasSuffix(version, ".*") {
+		version += ".*"
+	}
+	return s.Operator + version
+}
+
+// String joins the set back together.
+func (set SpecifierSet) String() string {
+	var parts []string
+	for _, spec := range set {
+		parts = append(parts, spec.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// Contains reports whether v satisfies the clause. Pre-releases are accepted,
+// callers that want to hide them should filter with Version.IsPreRelease.
+func (s Specifier) Contains(v Version) bool {
+	switch s.Operator {
+	case "===":
+		return strings.EqualFold(v.original, s.Version) || strings.EqualFold(v.String(), s.Version)
+	case "==":
+		if s.Wildcard {
+			return prefixMatch(s.version, v)
+		}
+		if len(s.version.Local) == 0 {
+			v = v.Public()
+		}
+		return Compare(v, s.version) == 0
+	case "!=":
+		if s.Wildcard {
+			return !prefixMatch(s.version, v)
+		}
+		if len(s.version.Local) == 0 {
+			v = v.Public()
+		}
+		return Compare(v, s.version) != 0
+	case "~=":
+		var prefix = s.version.BaseVersion()
+		prefix.Release = prefix.Release[:len(prefix.Release)-1]
+		return Compare(v.Public(), s.version) >= 0 && prefixMatch(prefix, v)
+	case "<=":
+		return Compare(v.Public(), s.version) <= 0
+	case ">=":
+		return Compare(v.Public(), s.version) >= 0
+	case "<":
+		if Compare(v.Public(), s.version) >= 0 {
+			return false
+		}
+		// <3.0 shouldn't let 3.0rc1 in unless the bound itself is a pre-release
+		if !s.version.IsPreRelease() && v.IsPreRelease() {
+			return Compare(v.BaseVersion(), s.version.BaseVersion()) != 0
+		}
+		return true
+	case ">":
+		if Compare(v.Public(), s.version) <= 0 {
+			return false
+		}
+		// >3.0 excludes 3.0.post1 and 3.0+local unless asked for explicitly
+		if !s.version.IsPostRelease() && v.IsPostRelease() && Compare(v.BaseVersion(), s.version.BaseVersion()) == 0 {
+			return false
+		}
+		return true
+	}
+
+	return false
+}
+
+// Contains reports whether v satisfies every clause in the set.
+func (set SpecifierSet) Contains(v Version) bool {
+	for _, spec := range set {
+		if !spec.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsString parses v first, invalid versions only match an empty set.
+func (set SpecifierSet) ContainsString(v string) bool {
+	var version, err = ParseVersion(v)
+	if err != nil {
+		return len(set) == 0
+	}
+	return set.Contains(version)
+}
+
+// LowerBound returns the version the set starts at, ok is false when the
+// set has no lower bound. Exclusive bounds are returned as is.
+func (set SpecifierSet) LowerBound() (Version, bool) {
+	var bound Version
+	var found bool
+	for _, spec := range set {
+		switch spec.Operator {
+		case ">=", "~=", ">", "==":
+			if !found || Compare(spec.version, bound) > 0 {
+				bound = spec.version
+				found = true
+			}
+		}
+	}
+	return bound, found
+}
+
+func prefixMatch(prefix Version, v Version) bool {
+	if prefix.Epoch != v.Epoch {
+		return false
+	}
+	for i := range prefix.Release {
+		if segment(v.Release, i) != prefix.Release[i] {
+			return false
+		}
+	}
+	return true
+}
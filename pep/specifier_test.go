@@ -0,0 +1,148 @@
+package pep
+
+import "testing"
+
+func TestSpecifierContains(t *testing.T) {
+	var cases = []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		// compatible release
+		{"~=2.2", "2.2", true},
+		{"~=2.2", "2.9.1", true},
+		{"~=2.2", "3.0", false},
+		{"~=2.2", "2.1", false},
+		{"~=1.4.5", "1.4.9", true},
+		{"~=1.4.5", "1.5.0", false},
+		{"~=1.4.5", "1.4.4", false},
+		{"~=1.4.5a4", "1.4.5", true},
+		{"~=1.4.5a4", "1.4.5a3", false},
+		{"~=2.2.post3", "2.3", true},
+		{"~=2.2.post3", "2.2", false},
+		{"~=1!2.2", "2.5", false},
+
+		// exact and wildcard matches
+		{"==1.1", "1.1.0", true},
+		{"==1.1", "1.1+local", true},
+		{"==1.1", "1.1.post1", false},
+		{"==1.1+local", "1.1", false},
+		{"==1.1+local", "1.1+local", true},
+		{"==1.1.*", "1.1", true},
+		{"==1.1.*", "1.1.9", true},
+		{"==1.1.*", "1.1.0.post1", true},
+		{"==1.1.*", "1.1.0a1", true},
+		{"==1.1.*", "1.10", false},
+		{"==1.1.*", "1.2", false},
+		{"==1.*", "1!1.0", false},
+		{"!=1.1.*", "1.1.3", false},
+		{"!=1.1.*", "1.2", true},
+		{"!=1.1", "1.1+local", false},
+		{"!=1.1", "1.1.post1", true},
+
+		// arbitrary equality compares the strings
+		{"===1.0", "1.0", true},
+		{"===1.0", "1.0.0", false},
+		{"===1.0", "1.0+local", false},
+		{"===1.0a1", "1.0A1", true},
+
+		// exclusive bounds keep out pre-releases of the bound and post-releases of it
+		{"<3.0", "2.9", true},
+		{"<3.0", "2.9rc1", true},
+		{"<3.0", "3.0rc1", false},
+		{"<3.0", "3.0.dev1", false},
+		{"<3.0", "3.0", false},
+		{"<3.0", "3.0+local", false},
+		{"<3", "3.0a1", false},
+		{"<3.0rc2", "3.0rc1", true},
+		{"<3.0rc2", "3.0rc2", false},
+		{">3.0", "3.1", true},
+		{">3.0", "3.0", false},
+		{">3.0", "3.0.post1", false},
+		{">3.0", "3.0+local", false},
+		{">3.0", "3.0.1", true},
+		{">3.0", "3.0.1.post1", true},
+		{">3.0.post1", "3.0.post2", true},
+		{">3.0.post1", "3.0.post1", false},
+		{">3.0rc1", "3.0", true},
+
+		// inclusive bounds
+		{"<=2.0", "2.0", true},
+		{"<=2.0", "2.0+local", true},
+		{"<=2.0", "2.0.post1", false},
+		{">=2.0", "2.0", true},
+		{">=2.0", "2.0rc1", false},
+		{">=2.0", "2.1a1", true},
+		{">=2.0", "1!1.0", true},
+	}
+	for _, c := range cases {
+		var spec, err = ParseSpecifier(c.spec)
+		if err != nil {
+			t.Errorf("ParseSpecifier(%q): %v", c.spec, err)
+			continue
+		}
+		var v, _ = ParseVersion(c.version)
+		if got := spec.Contains(v); got != c.want {
+			t.Errorf("%v contains %v = %v, want %v", c.spec, c.version, got, c.want)
+		}
+	}
+}
+
+func TestParseSpecifierInvalid(t *testing.T) {
+	for _, input := range []string{"", "1.0", "=>1.0", ">=", "~=1", ">=1.0.*", "~=1.*", "==1.0.*.*", "<latest"} {
+		if _, err := ParseSpecifier(input); err == nil {
+			t.Errorf("ParseSpecifier(%q) should fail", input)
+		}
+	}
+}
+
+func TestSpecifierSet(t *testing.T) {
+	var set, err = ParseSpecifierSet(" >=2.0, <3 ,!=2.5.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := set.String(); got != ">=2.0,<3,!=2.5.*" {
+		t.Errorf("String() = %v", got)
+	}
+	var cases = map[string]bool{
+		"1.9":     false,
+		"2.0":     true,
+		"2.5.1":   false,
+		"2.9.9":   true,
+		"3.0rc1":  false,
+		"3.0":     false,
+		"garbage": false,
+	}
+	for version, want := range cases {
+		if got := set.ContainsString(version); got != want {
+			t.Errorf("%v contains %v = %v, want %v", set, version, got, want)
+		}
+	}
+
+	var empty, _ = ParseSpecifierSet("")
+	if !empty.ContainsString("garbage") || !empty.ContainsString("1.0") {
+		t.Error("an empty set should match everything")
+	}
+}
+
+func TestLowerBound(t *testing.T) {
+	var cases = map[string]string{
+		">=3.8":            "3.8",
+		">=3.8,>3.9":       "3.9",
+		"~=3.10":           "3.10",
+		"==3.11.*":         "3.11",
+		"<4":               "",
+		">=3.7,!=3.8.*,<4": "3.7",
+	}
+	for input, want := range cases {
+		var set, _ = ParseSpecifierSet(input)
+		var bound, ok = set.LowerBound()
+		var got string
+		if ok {
+			got = bound.String()
+		}
+		if got != want {
+			t.Errorf("LowerBound(%v) = %q, want %q", input, got, want)
+		}
+	}
+}
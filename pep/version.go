@@ -0,0 +1,241 @@
+// Package pep implements the bits of the Python packaging specs lazypython
+// needs: PEP 440 versions and specifiers, PEP 508 requirement strings and
+// environment markers, and PEP 503 name normalization.
+package pep
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the canonical version pattern from the PEP 440 appendix
+var versionPattern = regexp.MustCompile(`(?i)^v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?P<pre>[-_.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?P<post>(?:-(?P<post_n1>[0-9]+))|(?:[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?))?` +
+	`(?P<dev>[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+var namePattern = regexp.MustCompile(`[-_.]+`)
+
+// Version is a parsed PEP 440 version. Post and Dev are -1 when absent.
+type Version struct {
+	Epoch    int
+	Release  []int
+	Pre      string
+	PreN     int
+	Post     int
+	Dev      int
+	Local    []string
+	original string
+}
+
+// Normalize returns the PEP 503 normalized form of a project name.
+func Normalize(name string) string {
+	return strings.ToLower(namePattern.ReplaceAllString(strings.TrimSpace(name), "-"))
+}
+
+// ParseVersion parses a PEP 440 version, accepting the usual alternative spellings.
+func ParseVersion(s string) (Version, error) {
+	var match = versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return Version{}, fmt.Errorf("invalid version: %q", s)
+	}
+
+	var group = func(name string) string {
+		return match[versionPattern.SubexpIndex(name)]
+	}
+	var number = func(s string) int {
+		var n, _ = strconv.Atoi(s)
+		return n
+	}
+
+	var v = Version{Post: -1, Dev: -1, original: strings.TrimSpace(s)}
+	v.Epoch = number(group("epoch"))
+	for _, part := range strings.Split(group("release"), ".") {
+		v.Release = append(v.Release, number(part))
+	}
+
+	if group("pre") != "" {
+		switch strings.ToLower(group("pre_l")) {
+		case "a", "alpha":
+			v.Pre = "a"
+		case "b", "beta":
+			v.Pre = "b"
+		default:
+			v.Pre = "rc"
+		}
+		v.PreN = number(group("pre_n"))
+	}
+
+	if group("post") != "" {
+		v.Post = number(group("post_n1") + group("post_n2"))
+	}
+	if group("dev") != "" {
+		v.Dev = number(group("dev_n"))
+	}
+	if local := group("local"); local != "" {
+		v.Local = strings.FieldsFunc(strings.ToLower(local), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+	}
+
+	return v, nil
+}
+
+// IsPreRelease reports whether the version is an alpha, beta, rc or dev release.
+func (v Version) IsPreRelease() bool {
+	return v.Pre != "" || v.Dev >= 0
+}
+
+// IsPostRelease reports whether the version carries a post release segment.
+func (v Version) IsPostRelease() bool {
+	return v.Post >= 0
+}
+
+// Public returns the version without its local segment.
+func (v Version) Public() Version {
+	v.Local = nil
+	return v
+}
+
+// BaseVersion returns just the epoch and release segments.
+func (v Version) BaseVersion() Version {
+	return Version{Epoch: v.Epoch, Release: v.Release, Post: -1, Dev: -1}
+}
+
+// String returns the normalized form of the version.
+func (v Version) String() string {
+	var b strings.Builder
+	if v.Epoch != 0 {
+		fmt.Fprintf(&b, "%d!", v.Epoch)
+	}
+	for i, part := range v.Release {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(part))
+	}
+	if v.Pre != "" {
+		fmt.Fprintf(&b, "%v%d", v.Pre, v.PreN)
+	}
+	if v.Post >= 0 {
+		fmt.Fprintf(&b, ".post%d", v.Post)
+	}
+	if v.Dev >= 0 {
+		fmt.Fprintf(&b, ".dev%d", v.Dev)
+	}
+	if len(v.Local) > 0 {
+		b.WriteString("+" + strings.Join(v.Local, "."))
+	}
+	return b.String()
+}
+
+// Compare returns -1, 0 or 1 following PEP 440 ordering.
+func Compare(a Version, b Version) int {
+	if c := compareInt(a.Epoch, b.Epoch); c != 0 {
+		return c
+	}
+
+	for i := 0; i < len(a.Release) || i < len(b.Release); i++ {
+		if c := compareInt(segment(a.Release, i), segment(b.Release, i)); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInt(preKey(a), preKey(b)); c != 0 {
+		return c
+	}
+	if a.Pre != "" && b.Pre != "" {
+		if c := compareInt(a.PreN, b.PreN); c != 0 {
+			return c
+		}
+	}
+
+	if c := compareInt(a.Post, b.Post); c != 0 {
+		return c
+	}
+
+	// no dev segment sorts after any dev release
+	var adev, bdev = a.Dev, b.Dev
+	if adev < 0 {
+		adev = int(^uint(0) >> 1)
+	}
+	if bdev < 0 {
+		bdev = int(^uint(0) >> 1)
+	}
+	if c := compareInt(adev, bdev); c != 0 {
+		return c
+	}
+
+	return compareLocal(a.Local, b.Local)
+}
+
+// CompareStrings parses and compares two versions, falling back to a plain
+// string comparison when either one isn't valid PEP 440.
+func CompareStrings(a string, b string) int {
+	var va, aerr = ParseVersion(a)
+	var vb, berr = ParseVersion(b)
+	if aerr != nil || berr != nil {
+		return strings.Compare(a, b)
+	}
+	return Compare(va, vb)
+}
+
+func segment(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// a dev-only release sorts before any pre-release, a final release after all of them
+func preKey(v Version) int {
+	switch {
+	case v.Pre == "" && v.Post < 0 && v.Dev >= 0:
+		return -1
+	case v.Pre == "":
+		return 4
+	case v.Pre == "a":
+		return 1
+	case v.Pre == "b":
+		return 2
+	}
+	return 3
+}
+
+// numeric local segments sort after alphanumeric ones, longer locals win ties
+func compareLocal(a []string, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		var an, aerr = strconv.Atoi(a[i])
+		var bn, berr = strconv.Atoi(b[i])
+		switch {
+		case aerr == nil && berr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aerr == nil:
+			return 1
+		case berr == nil:
+			return -1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
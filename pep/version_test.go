@@ -0,0 +1,133 @@
+package pep
+
+import "testing"
+
+// every version sorts after the one before it, PEP 440's own examples plus epochs and locals
+var orderedVersions = []string{
+	"1.0.dev456",
+	"1.0a1.dev456",
+	"1.0a1",
+	"1.0a2.dev456",
+	"1.0a12.dev456",
+	"1.0a12",
+	"1.0b1.dev456",
+	"1.0b2",
+	"1.0b2.post345.dev456",
+	"1.0b2.post345",
+	"1.0rc1.dev456",
+	"1.0rc1",
+	"1.0",
+	"1.0+abc.5",
+	"1.0+abc.7",
+	"1.0+5",
+	"1.0.post456.dev34",
+	"1.0.post456",
+	"1.0.15",
+	"1.1.dev1",
+	"1.1",
+	"2.0",
+	"1!0.5",
+}
+
+func TestCompareOrdering(t *testing.T) {
+	for i := range orderedVersions {
+		for j := range orderedVersions {
+			var want = compareInt(i, j)
+			if got := CompareStrings(orderedVersions[i], orderedVersions[j]); got != want {
+				t.Errorf("Compare(%v, %v) = %v, want %v", orderedVersions[i], orderedVersions[j], got, want)
+			}
+		}
+	}
+}
+
+func TestCompareEqual(t *testing.T) {
+	var cases = [][2]string{
+		{"1.0", "1.0.0"},
+		{"1", "1.0.0.0"},
+		{"0!1.0", "1.0"},
+		{"1.0a1", "1.0.alpha.1"},
+		{"1.0+ubuntu-1", "1.0+ubuntu.1"},
+		{"v2.1", "2.1"},
+	}
+	for _, c := range cases {
+		if got := CompareStrings(c[0], c[1]); got != 0 {
+			t.Errorf("Compare(%v, %v) = %v, want 0", c[0], c[1], got)
+		}
+	}
+}
+
+func TestParseVersionNormalizes(t *testing.T) {
+	var cases = map[string]string{
+		"1.0":              "1.0",
+		"01.002":           "1.2",
+		"v1.0":             "1.0",
+		"1.0alpha1":        "1.0a1",
+		"1.0-beta.2":       "1.0b2",
+		"1.0c1":            "1.0rc1",
+		"1.0preview3":      "1.0rc3",
+		"1.0a":             "1.0a0",
+		"1.0-1":            "1.0.post1",
+		"1.0.post":         "1.0.post0",
+		"1.0-r4":           "1.0.post4",
+		"1.0rev2":          "1.0.post2",
+		"1.0.DEV":          "1.0.dev0",
+		"1.0-dev-3":        "1.0.dev3",
+		"1!2.0RC1.POST2":   "1!2.0rc1.post2",
+		"1.0+Ubuntu-1":     "1.0+ubuntu.1",
+		"  2.0.post1.dev3": "2.0.post1.dev3",
+	}
+	for input, want := range cases {
+		var v, err = ParseVersion(input)
+		if err != nil {
+			t.Errorf("ParseVersion(%q): %v", input, err)
+			continue
+		}
+		if got := v.String(); got != want {
+			t.Errorf("ParseVersion(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, input := range []string{"", "latest", "1.0-", "1.0+", "1.0+local+again", "a1.0", "1..0"} {
+		if _, err := ParseVersion(input); err == nil {
+			t.Errorf("ParseVersion(%q) should fail", input)
+		}
+	}
+}
+
+func TestVersionKinds(t *testing.T) {
+	var cases = []struct {
+		version string
+		pre     bool
+		post    bool
+	}{
+		{"1.0", false, false},
+		{"1.0a1", true, false},
+		{"1.0.dev1", true, false},
+		{"1.0.post1", false, true},
+		{"1.0.post1.dev1", true, true},
+		{"1.0+local", false, false},
+	}
+	for _, c := range cases {
+		var v, _ = ParseVersion(c.version)
+		if v.IsPreRelease() != c.pre || v.IsPostRelease() != c.post {
+			t.Errorf("%v: pre = %v, post = %v, want %v, %v", c.version, v.IsPreRelease(), v.IsPostRelease(), c.pre, c.post)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var cases = map[string]string{
+		"requests":          "requests",
+		"Django":            "django",
+		"zope.interface":    "zope-interface",
+		"typing_extensions": "typing-extensions",
+		"Foo__Bar-.baz":     "foo-bar-baz",
+	}
+	for input, want := range cases {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
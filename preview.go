@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"lazypython/pep"
+)
+
+// managers that can resolve an install without touching the environment, the others install unseen
+type installPreviewer interface {
+	dryRun(ctx context.Context, env pythonEnv, requirement string) ([]plannedPackage, error)
+}
+
+// a distribution the resolver would install, url is only known when the resolver says where from
+type plannedPackage struct {
+	name    string
+	version string
+	url     string
+}
+
+const (
+	changeAdd       = "new"
+	changeUpgrade   = "upgrade"
+	changeDowngrade = "downgrade"
+	changeReinstall = "reinstall"
+)
+
+type plannedChange struct {
+	name string
+	from string
+	to   string
+	kind string
+	// bytes to download, 0 when the index couldn't tell
+	size int64
+}
+
+type InstallPreviewMsg struct {
+	preview installPreview
+	err     error
+	// the screen context the dry run ran under, canceled when the user left before it finished
+	ctx context.Context
+}
+
+type installPreview struct {
+	requirement string
+	changes     []plannedChange
+	conflicts   []string
+}
+
+// pip 22.2+ writes its resolution as JSON, --quiet keeps stdout to just the report
+func (pipManager) dryRun(ctx context.Context, env pythonEnv, requirement string) ([]plannedPackage, error) {
+	var output, err = managerCommandContext(ctx, "pip", env, "install", "--dry-run", "--quiet", "--report", "-", requirement).Output()
+	if err != nil {
+		return nil, commandError(err)
+	}
+
+	var report struct {
+		Install []struct {
+			DownloadInfo struct {
+				URL string `json:"url"`
+			} `json:"download_info"`
+			Metadata struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"metadata"`
+		} `json:"install"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("pip --report: %w", err)
+	}
+
+	var planned []plannedPackage
+	for _, item := range report.Install {
+		planned = append(planned, plannedPackage{name: item.Metadata.Name, version: item.Metadata.Version, url: item.DownloadInfo.URL})
+	}
+	return planned, nil
+}
+
+// uv add has no dry run, uv pip install resolves the same requirement against the environment
+func (uvManager) dryRun(ctx context.Context, env pythonEnv, requirement string) ([]plannedPackage, error) {
+	var output, err = managerCommandContext(ctx, "uv", env, "pip", "install", "--dry-run", "--python", env.interpreter, requirement).CombinedOutput()
+	if err != nil {
+		return nil, errors.New(strings.TrimSpace(string(output)))
+	}
+	return parseUvDryRun(string(output)), nil
+}
+
+// " + requests==2.32.3" is installed, " - urllib3==1.26.0" goes away and " ~ pkg==1.0" is reinstalled
+func parseUvDryRun(output string) []plannedPackage {
+	var planned []plannedPackage
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "+ ") && !strings.HasPrefix(line, "~ ") {
+			continue
+		}
+		var name, version, found = strings.Cut(strings.TrimSpace(line[2:]), "==")
+		if !found {
+			continue
+		}
+		planned = append(planned, plannedPackage{name: name, version: version})
+	}
+	return planned
+}
+
+// the stderr of a command that failed, the exit status when it printed nothing
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(strings.TrimSpace(string(exitErr.Stderr))) > 0 {
+		return errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// compares the resolver's plan with what's installed, looks up download sizes and checks the packages
+// that stay put still accept the versions that would replace their dependencies
+func previewInstall(ctx context.Context, client indexClient, previewer installPreviewer, env pythonEnv, pkgs []pythonPackage, requirement string) (installPreview, error) {
+	var preview = installPreview{requirement: requirement}
+	var planned, err = previewer.dryRun(ctx, env, requirement)
+	if err != nil {
+		return preview, err
+	}
+
+	var installed = make(map[string]string)
+	for _, pkg := range pkgs {
+		if pkg.installed {
+			installed[pep.Normalize(pkg.path)] = pkg.version
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var limit = make(chan struct{}, 8)
+	for _, pkg := range planned {
+		var change = plannedChange{name: pkg.name, to: pkg.version, kind: changeAdd}
+		if from, ok := installed[pep.Normalize(pkg.name)]; ok {
+			change.from = from
+			switch cmp := compareVersions(pkg.version, from); {
+			case cmp > 0:
+				change.kind = changeUpgrade
+			case cmp < 0:
+				change.kind = changeDowngrade
+			default:
+				change.kind = changeReinstall
+			}
+		}
+
+		wg.Add(1)
+		go func(pkg plannedPackage, change plannedChange) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			if files, err := getReleaseFiles(ctx, client, pkg.name, pkg.version); err == nil {
+				change.size = downloadSize(files, pkg.url)
+			}
+			mu.Lock()
+			preview.changes = append(preview.changes, change)
+			mu.Unlock()
+		}(pkg, change)
+	}
+	wg.Wait()
+
+	sort.SliceStable(preview.changes, func(i, j int) bool {
+		return pep.Normalize(preview.changes[i].name) < pep.Normalize(preview.changes[j].name)
+	})
+	preview.conflicts = plannedConflicts(env, planned)
+	return preview, nil
+}
+
+// the file the resolver picked when it said which, otherwise the universal wheel, otherwise unknown
+func downloadSize(files []releaseFile, url string) int64 {
+	for _, file := range files {
+		if url != "" && file.URL == url {
+			return file.Size
+		}
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Filename, "-none-any.whl") {
+			return file.Size
+		}
+	}
+	return 0
+}
+
+// installed packages whose requirements the plan would break, pip only warns about these afterwards
+func plannedConflicts(env pythonEnv, planned []plannedPackage) []string {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return nil
+	}
+
+	var versions = make(map[string]string)
+	for _, pkg := range planned {
+		versions[pep.Normalize(pkg.name)] = pkg.version
+	}
+
+	var markerEnv = pythonEnvironment(env.interpreter)
+	var conflicts []string
+	for _, dist := range readInstalledDistributions(dirs) {
+		if _, replaced := versions[pep.Normalize(dist.name)]; replaced {
+			continue
+		}
+		for _, line := range dist.requiresDist {
+			var req, err = pep.ParseRequirement(line)
+			if err != nil || !req.Applies(markerEnv) {
+				continue
+			}
+			var version, ok = versions[req.NormalizedName()]
+			if !ok || req.Specifier.ContainsString(version) {
+				continue
+			}
+			conflicts = append(conflicts, fmt.Sprintf("%v %v requires %v%v, would get %v", dist.name, dist.version, req.Name, req.Specifier, version))
+		}
+	}
+	return conflicts
+}
+
+func formatSize(bytes int64) string {
+	switch {
+	case bytes <= 0:
+		return "?"
+	case bytes < 1024:
+		return fmt.Sprintf("%v B", bytes)
+	case bytes < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+}
+
+// the plan as the confirm dialog shows it, long plans are cut short
+func (p installPreview) String() string {
+	const maxLines = 15
+
+	var lines []string
+	var total int64
+	for _, change := range p.changes {
+		total += change.size
+		var marker, versions = "+", change.to
+		switch change.kind {
+		case changeUpgrade:
+			marker, versions = "↑", change.from+" → "+change.to
+		case changeDowngrade:
+			marker, versions = "↓", change.from+" → "+change.to
+		case changeReinstall:
+			marker = "~"
+		}
+		lines = append(lines, fmt.Sprintf("%v %v %v (%v, %v)", marker, change.name, versions, change.kind, formatSize(change.size)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "Nothing to install, the requirement is already satisfied")
+	}
+	if len(lines) > maxLines {
+		lines = append(lines[:maxLines], fmt.Sprintf("... and %v more", len(lines)-maxLines))
+	}
+	if total > 0 {
+		lines = append(lines, "", fmt.Sprintf("Download: %v", formatSize(total)))
+	}
+	for i, conflict := range p.conflicts {
+		if i == maxLines/3 {
+			lines = append(lines, fmt.Sprintf("! ... and %v more conflicts", len(p.conflicts)-i))
+			break
+		}
+		if i == 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "! "+conflict)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// a single line text prompt, the counterpart of confirmDialog for actions that need a value
+type promptDialog struct {
+	title    string
+	input    textinput.Model
+	onSubmit func(m *model, value string) tea.Cmd
+}
+
+func openPromptDialog(m *model, title string, value string, onSubmit func(m *model, value string) tea.Cmd) {
+	var input = textinput.New()
+	input.CharLimit = -1
+	input.Width = m.window.width / 2
+	input.SetValue(value)
+	input.Focus()
+	m.promptDialog = &promptDialog{title: title, input: input, onSubmit: onSubmit}
+}
+
+func updatePromptDialog(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		var dialog = m.promptDialog
+		m.promptDialog = nil
+		return dialog.onSubmit(m, dialog.input.Value())
+	case "esc":
+		m.promptDialog = nil
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.promptDialog.input, cmd = m.promptDialog.input.Update(msg)
+	return cmd
+}
+
+func drawPromptScreen(m *model) string {
+	var title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("229")).
+		Render(m.promptDialog.title)
+
+	var input = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(0, 1).
+		MarginTop(1).
+		Render(m.promptDialog.input.View())
+
+	var hint = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		MarginTop(1).
+		Render("Enter: confirm • Esc: cancel")
+
+	var box = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 3).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, input, hint))
+
+	return lipgloss.Place(m.window.width, m.window.height, lipgloss.Center, lipgloss.Center, box)
+}
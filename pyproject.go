@@ -0,0 +1,561 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"lazypython/pep"
+)
+
+const pyprojectFile = "pyproject.toml"
+
+// go-toml can't write a document back without losing comments and layout, so dependency arrays
+// are edited as text: find the array, work out where each string sits, splice the change in
+
+type tomlArrayItem struct {
+	value string
+	start int
+	end   int
+	comma int
+	quote byte
+	table bool
+}
+
+type tomlArray struct {
+	open  int
+	close int
+	items []tomlArrayItem
+}
+
+// a place that holds a list of requirement strings
+type dependencyList struct {
+	table string
+	key   string
+}
+
+func projectDependencies() dependencyList {
+	return dependencyList{table: "project", key: "dependencies"}
+}
+
+func dependencyGroup(name string) dependencyList {
+	return dependencyList{table: "dependency-groups", key: name}
+}
+
+func optionalDependency(name string) dependencyList {
+	return dependencyList{table: "project.optional-dependencies", key: name}
+}
+
+// project, [extra] or the group name, the same form parseDependencyList reads back
+func (l dependencyList) String() string {
+	switch l.table {
+	case "project":
+		return "project"
+	case "project.optional-dependencies":
+		return "[" + l.key + "]"
+	}
+	return l.key
+}
+
+func (l dependencyList) isGroup() bool {
+	return l.table == "dependency-groups"
+}
+
+func (l dependencyList) isExtra() bool {
+	return l.table == "project.optional-dependencies"
+}
+
+var dependencyGroupNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+
+func parseDependencyList(value string) (dependencyList, error) {
+	value = strings.TrimSpace(value)
+	var list = dependencyGroup(value)
+	switch {
+	case value == "project":
+		return projectDependencies(), nil
+	case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+		list = optionalDependency(strings.TrimSpace(value[1 : len(value)-1]))
+	}
+	if !dependencyGroupNamePattern.MatchString(list.key) {
+		return list, fmt.Errorf("%q is not a valid group or extra name", value)
+	}
+	return list, nil
+}
+
+var tomlHeaderPattern = regexp.MustCompile(`(?m)^[ \t]*\[`)
+var includeGroupPattern = regexp.MustCompile(`include-group[ \t]*=[ \t]*["']([^"']+)["']`)
+
+// returns where the table's body starts and ends, -1 when it isn't there
+func findTomlTable(src string, table string) (int, int) {
+	var header = regexp.MustCompile(`(?m)^[ \t]*\[[ \t]*` + regexp.QuoteMeta(table) + `[ \t]*\][ \t]*(#.*)?$`)
+	var loc = header.FindStringIndex(src)
+	if loc == nil {
+		return -1, -1
+	}
+
+	var start = loc[1]
+	if start < len(src) && src[start] == '\n' {
+		start++
+	}
+
+	// the next header ends the table, array lines starting with '[' would fool this but don't happen in practice
+	var end = len(src)
+	var offset = start
+	for {
+		var next = tomlHeaderPattern.FindStringIndex(src[offset:])
+		if next == nil {
+			break
+		}
+		var lineStart = offset + next[0]
+		if !insideTomlArray(src[start:lineStart]) {
+			end = lineStart
+			break
+		}
+		offset = offset + next[1]
+	}
+	return start, end
+}
+
+// true when the text has an unclosed '[' outside of strings and comments
+func insideTomlArray(text string) bool {
+	var depth int
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '#':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+		case '"', '\'':
+			i = skipTomlString(text, i) - 1
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// index just past the string literal starting at i
+func skipTomlString(src string, i int) int {
+	var quote = src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch {
+		case quote == '"' && src[j] == '\\':
+			j++
+		case src[j] == quote:
+			return j + 1
+		case src[j] == '\n':
+			return j
+		}
+	}
+	return len(src)
+}
+
+func findTomlArray(src string, list dependencyList) (tomlArray, bool) {
+	var start, end = findTomlTable(src, list.table)
+	if start < 0 {
+		return tomlArray{}, false
+	}
+
+	var key = regexp.MustCompile(`(?m)^[ \t]*(` + regexp.QuoteMeta(list.key) + `|"` + regexp.QuoteMeta(list.key) + `"|'` + regexp.QuoteMeta(list.key) + `')[ \t]*=[ \t]*\[`)
+	var loc = key.FindStringIndex(src[start:end])
+	if loc == nil {
+		return tomlArray{}, false
+	}
+	return parseTomlArray(src, start+loc[1]-1)
+}
+
+func parseTomlArray(src string, open int) (tomlArray, bool) {
+	var array = tomlArray{open: open}
+	for i := open + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			var end = skipTomlString(src, i)
+			var value = src[i+1 : end-1]
+			if c == '"' {
+				value = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+			}
+			array.items = append(array.items, tomlArrayItem{value: value, start: i, end: end, comma: -1, quote: c})
+			i = end - 1
+		case c == '{':
+			// {include-group = "..."} entries in dependency groups, value holds the included group
+			var end = strings.IndexByte(src[i:], '}')
+			if end < 0 {
+				return array, false
+			}
+			var item = tomlArrayItem{start: i, end: i + end + 1, comma: -1, table: true}
+			if match := includeGroupPattern.FindStringSubmatch(src[i : i+end]); match != nil {
+				item.value = match[1]
+			}
+			array.items = append(array.items, item)
+			i += end
+		case c == ',':
+			if len(array.items) > 0 {
+				array.items[len(array.items)-1].comma = i
+			}
+		case c == ']':
+			array.close = i
+			return array, true
+		}
+	}
+	return array, false
+}
+
+func quoteTomlString(value string) string {
+	if strings.Contains(value, `"`) && !strings.Contains(value, `'`) {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+func lineBounds(src string, i int) (int, int) {
+	var start = strings.LastIndexByte(src[:i], '\n') + 1
+	var end = strings.IndexByte(src[i:], '\n')
+	if end < 0 {
+		return start, len(src)
+	}
+	return start, i + end
+}
+
+func addToTomlArray(src string, list dependencyList, value string) string {
+	var array, ok = findTomlArray(src, list)
+	if !ok {
+		return createTomlArray(src, list, value)
+	}
+
+	var quoted = quoteTomlString(value)
+	var multiline = strings.Contains(src[array.open:array.close], "\n")
+
+	if len(array.items) == 0 {
+		if multiline {
+			return src[:array.open+1] + "\n    " + quoted + "," + src[array.open+1:]
+		}
+		return src[:array.open+1] + quoted + src[array.close:]
+	}
+
+	var last = array.items[len(array.items)-1]
+	if !multiline {
+		if last.comma >= 0 {
+			return src[:last.comma+1] + " " + quoted + "," + src[last.comma+1:]
+		}
+		return src[:last.end] + ", " + quoted + src[last.end:]
+	}
+
+	// match the indentation of the last entry and whether it kept a trailing comma
+	var lineStart, lineEnd = lineBounds(src, last.start)
+	var indent = src[lineStart:last.start]
+	if strings.TrimSpace(indent) != "" {
+		indent = "    "
+	}
+	if lineEnd > array.close {
+		lineEnd = array.close
+	}
+
+	if last.comma >= 0 {
+		return src[:lineEnd] + "\n" + indent + quoted + "," + src[lineEnd:]
+	}
+	return src[:last.end] + "," + src[last.end:lineEnd] + "\n" + indent + quoted + src[lineEnd:]
+}
+
+// a new array with a single value, or an empty one when value is ""
+func createTomlArray(src string, list dependencyList, value string) string {
+	var key = list.key
+	if strings.ContainsAny(key, ". ") {
+		key = quoteTomlString(key)
+	}
+	var entry = fmt.Sprintf("%v = [\n    %v,\n]\n", key, quoteTomlString(value))
+	if value == "" {
+		entry = key + " = []\n"
+	}
+
+	var start, end = findTomlTable(src, list.table)
+	if start < 0 {
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += "\n"
+		}
+		if src != "" {
+			src += "\n"
+		}
+		return src + "[" + list.table + "]\n" + entry
+	}
+
+	// after the table's last non blank line so the spacing before the next table survives
+	var body = strings.TrimRight(src[start:end], " \t\n")
+	var insert = start + len(body)
+	if body == "" {
+		return src[:insert] + entry + src[insert:]
+	}
+	if insert < len(src) && src[insert] == '\n' {
+		insert++
+	} else {
+		entry = "\n" + entry
+	}
+	return src[:insert] + entry + src[insert:]
+}
+
+func removeFromTomlArray(src string, array tomlArray, index int) string {
+	var item = array.items[index]
+
+	var lineStart, lineEnd = lineBounds(src, item.start)
+	var after = item.end
+	if item.comma >= 0 {
+		after = item.comma + 1
+	}
+
+	// alone on its line (maybe with a comment), drop the whole line
+	var before = strings.TrimSpace(src[lineStart:item.start])
+	var rest = strings.TrimSpace(src[after:lineEnd])
+	if before == "" && (rest == "" || strings.HasPrefix(rest, "#")) && lineEnd < array.close {
+		return src[:lineStart] + src[lineEnd+1:]
+	}
+
+	if item.comma < 0 && index > 0 {
+		// last entry without a trailing comma, take the previous comma with it
+		var previous = array.items[index-1]
+		return src[:previous.end] + src[item.end:]
+	}
+	if index > 0 && index == len(array.items)-1 {
+		// last entry with a trailing comma, the previous comma becomes the trailing one
+		var previous = array.items[index-1]
+		return src[:previous.comma+1] + src[after:]
+	}
+
+	var end = after
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[:item.start] + src[end:]
+}
+
+func findDependency(array tomlArray, name string) int {
+	for i, item := range array.items {
+		if item.table {
+			continue
+		}
+		if req, err := pep.ParseRequirement(item.value); err == nil && req.NormalizedName() == pep.Normalize(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func readPyproject() (string, error) {
+	var data, err = os.ReadFile(pyprojectFile)
+	return string(data), err
+}
+
+func writePyproject(src string) error {
+	return os.WriteFile(pyprojectFile, []byte(src), 0644)
+}
+
+// adds the requirement, or replaces the existing entry for the same package
+func addDependency(list dependencyList, requirement string) error {
+	var req, err = pep.ParseRequirement(requirement)
+	if err != nil {
+		return err
+	}
+
+	src, err := readPyproject()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if array, ok := findTomlArray(src, list); ok {
+		if i := findDependency(array, req.Name); i >= 0 {
+			return writePyproject(replaceTomlArrayItem(src, array.items[i], requirement))
+		}
+	}
+	return writePyproject(addToTomlArray(src, list, requirement))
+}
+
+func removeDependency(list dependencyList, name string) error {
+	var src, err = readPyproject()
+	if err != nil {
+		return err
+	}
+
+	var array, ok = findTomlArray(src, list)
+	if !ok {
+		return fmt.Errorf("%v has no %v dependencies", pyprojectFile, list)
+	}
+	var i = findDependency(array, name)
+	if i < 0 {
+		return fmt.Errorf("%v is not a %v dependency", name, list)
+	}
+	return writePyproject(removeFromTomlArray(src, array, i))
+}
+
+// swaps the requirement string for the package in place, keeping its quote style when possible
+func changeDependency(list dependencyList, name string, requirement string) error {
+	if _, err := pep.ParseRequirement(requirement); err != nil {
+		return err
+	}
+
+	var src, err = readPyproject()
+	if err != nil {
+		return err
+	}
+
+	var array, ok = findTomlArray(src, list)
+	if !ok {
+		return fmt.Errorf("%v has no %v dependencies", pyprojectFile, list)
+	}
+	var i = findDependency(array, name)
+	if i < 0 {
+		return fmt.Errorf("%v is not a %v dependency", name, list)
+	}
+	return writePyproject(replaceTomlArrayItem(src, array.items[i], requirement))
+}
+
+func replaceTomlArrayItem(src string, item tomlArrayItem, value string) string {
+	var quoted = quoteTomlString(value)
+	if item.quote == '\'' && !strings.Contains(value, "'") {
+		quoted = "'" + value + "'"
+	}
+	return src[:item.start] + quoted + src[item.end:]
+}
+
+// creates the group as an empty list, an existing group is left alone
+func createDependencyGroup(name string) error {
+	var src, err = readPyproject()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if _, ok := findTomlArray(src, dependencyGroup(name)); ok {
+		return fmt.Errorf("group %v already exists", name)
+	}
+	return writePyproject(createTomlArray(src, dependencyGroup(name), ""))
+}
+
+// moves the package's requirement string as is, added to the target before it leaves the source
+func moveDependency(from dependencyList, to dependencyList, name string) error {
+	var src, err = readPyproject()
+	if err != nil {
+		return err
+	}
+
+	var array, ok = findTomlArray(src, from)
+	if !ok {
+		return fmt.Errorf("%v has no %v dependencies", pyprojectFile, from)
+	}
+	var i = findDependency(array, name)
+	if i < 0 {
+		return fmt.Errorf("%v is not a %v dependency", name, from)
+	}
+	if err := addDependency(to, array.items[i].value); err != nil {
+		return err
+	}
+	return removeDependency(from, name)
+}
+
+var tomlKeyPattern = regexp.MustCompile(`(?m)^[ \t]*("[^"]+"|'[^']+'|[A-Za-z0-9_.-]+)[ \t]*=`)
+
+// every dependency list in the file, project dependencies first, then extras, then groups
+func listDependencyLists(src string) []dependencyList {
+	var lists []dependencyList
+	if _, ok := findTomlArray(src, projectDependencies()); ok {
+		lists = append(lists, projectDependencies())
+	}
+
+	for _, table := range []string{"project.optional-dependencies", "dependency-groups"} {
+		var start, end = findTomlTable(src, table)
+		if start < 0 {
+			continue
+		}
+		var body = src[start:end]
+		for _, match := range tomlKeyPattern.FindAllStringSubmatchIndex(body, -1) {
+			if !insideTomlArray(body[:match[0]]) {
+				lists = append(lists, dependencyList{table: table, key: strings.Trim(body[match[2]:match[3]], `"'`)})
+			}
+		}
+	}
+	return lists
+}
+
+// groups included by this one through {include-group = "..."}
+func includedGroups(src string, list dependencyList) []string {
+	var array, ok = findTomlArray(src, list)
+	if !ok {
+		return nil
+	}
+	var groups []string
+	for _, item := range array.items {
+		if item.table && item.value != "" {
+			groups = append(groups, item.value)
+		}
+	}
+	return groups
+}
+
+// the requirements installing a list means: included groups are expanded and an extra that
+// names its own project (proj[other]) pulls in the other extra, each list is visited once
+func resolveDependencyList(src string, list dependencyList, project string) []string {
+	var requirements []string
+	var visited = make(map[dependencyList]bool)
+	var visit func(list dependencyList)
+	visit = func(list dependencyList) {
+		if visited[list] {
+			return
+		}
+		visited[list] = true
+
+		for _, requirement := range dependencyStrings(src, list) {
+			var req, err = pep.ParseRequirement(requirement)
+			if err == nil && project != "" && req.NormalizedName() == pep.Normalize(project) {
+				for _, extra := range req.Extras {
+					visit(optionalDependency(extra))
+				}
+				continue
+			}
+			requirements = append(requirements, requirement)
+		}
+		for _, group := range includedGroups(src, list) {
+			visit(dependencyGroup(group))
+		}
+	}
+	visit(list)
+	return requirements
+}
+
+func dependencyStrings(src string, list dependencyList) []string {
+	var array, ok = findTomlArray(src, list)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, item := range array.items {
+		if !item.table {
+			values = append(values, item.value)
+		}
+	}
+	return values
+}
+
+// uv add, poetry add and friends keep pyproject.toml up to date on their own, conda's packages don't
+// go there at all, pip needs us to do it
+func declareInstalledDependency(manager packageManager, requirement string) error {
+	if manager.skipsPyprojectEdits() || !fileExists(pyprojectFile) {
+		return nil
+	}
+	return addDependency(projectDependencies(), requirement)
+}
+
+func undeclareRemovedDependency(manager packageManager, name string) error {
+	if manager.skipsPyprojectEdits() || !fileExists(pyprojectFile) {
+		return nil
+	}
+	var src, err = readPyproject()
+	if err != nil {
+		return err
+	}
+	if array, ok := findTomlArray(src, projectDependencies()); !ok || findDependency(array, name) < 0 {
+		return nil
+	}
+	return removeDependency(projectDependencies(), name)
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestAddToTomlArray(t *testing.T) {
+	var cases = []struct {
+		name  string
+		list  dependencyList
+		value string
+		src   string
+		want  string
+	}{
+		{
+			name:  "single line",
+			list:  projectDependencies(),
+			value: "httpx>=0.27",
+			src:   "[project]\ndependencies = [\"requests\", \"rich\"]\n",
+			want:  "[project]\ndependencies = [\"requests\", \"rich\", \"httpx>=0.27\"]\n",
+		},
+		{
+			name:  "single line trailing comma",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\"requests\",]\n",
+			want:  "[project]\ndependencies = [\"requests\", \"httpx\",]\n",
+		},
+		{
+			name:  "single line empty",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = []\n",
+			want:  "[project]\ndependencies = [\"httpx\"]\n",
+		},
+		{
+			name:  "multiline trailing comma",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n  \"requests\",\n  \"rich\",\n]\n",
+			want:  "[project]\ndependencies = [\n  \"requests\",\n  \"rich\",\n  \"httpx\",\n]\n",
+		},
+		{
+			name:  "multiline without trailing comma",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n    \"requests\",\n    \"rich\"\n]\n",
+			want:  "[project]\ndependencies = [\n    \"requests\",\n    \"rich\",\n    \"httpx\"\n]\n",
+		},
+		{
+			name:  "multiline empty",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n]\n",
+			want:  "[project]\ndependencies = [\n    \"httpx\",\n]\n",
+		},
+		{
+			name:  "inline comment after the last entry",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n    \"requests\",  # http\n    \"rich\"  # pretty\n]\n",
+			want:  "[project]\ndependencies = [\n    \"requests\",  # http\n    \"rich\",  # pretty\n    \"httpx\"\n]\n",
+		},
+		{
+			name:  "comments with brackets and quotes",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n    # don't [pin] this\n    \"requests\",\n]\n",
+			want:  "[project]\ndependencies = [\n    # don't [pin] this\n    \"requests\",\n    \"httpx\",\n]\n",
+		},
+		{
+			name:  "hash inside a string",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n    \"pkg @ https://example.com/pkg.zip#sha256=abc\",\n]\n",
+			want:  "[project]\ndependencies = [\n    \"pkg @ https://example.com/pkg.zip#sha256=abc\",\n    \"httpx\",\n]\n",
+		},
+		{
+			name:  "double quotes in the value",
+			list:  projectDependencies(),
+			value: `tomli; python_version < "3.11"`,
+			src:   "[project]\ndependencies = ['requests']\n",
+			want:  "[project]\ndependencies = ['requests', 'tomli; python_version < \"3.11\"']\n",
+		},
+		{
+			name:  "after an include-group",
+			list:  dependencyGroup("dev"),
+			value: "ruff",
+			src:   "[dependency-groups]\ntest = [\"pytest\"]\ndev = [\n    {include-group = \"test\"},\n]\n",
+			want:  "[dependency-groups]\ntest = [\"pytest\"]\ndev = [\n    {include-group = \"test\"},\n    \"ruff\",\n]\n",
+		},
+		{
+			name:  "closing bracket on the last line",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\ndependencies = [\n    \"requests\",\n    \"rich\"]\n",
+			want:  "[project]\ndependencies = [\n    \"requests\",\n    \"rich\",\n    \"httpx\"]\n",
+		},
+		{
+			name:  "quoted key",
+			list:  dependencyGroup("dev"),
+			value: "ruff",
+			src:   "[dependency-groups]\n\"dev\" = [\"pytest\"]\n",
+			want:  "[dependency-groups]\n\"dev\" = [\"pytest\", \"ruff\"]\n",
+		},
+		{
+			name:  "missing array in an existing table",
+			list:  projectDependencies(),
+			value: "httpx",
+			src:   "[project]\nname = \"demo\"\n\n[tool.ruff]\nline-length = 100\n",
+			want:  "[project]\nname = \"demo\"\ndependencies = [\n    \"httpx\",\n]\n\n[tool.ruff]\nline-length = 100\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := addToTomlArray(c.src, c.list, c.value); got != c.want {
+				t.Errorf("got\n%v\nwant\n%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRemoveFromTomlArray(t *testing.T) {
+	var cases = []struct {
+		name string
+		list dependencyList
+		pkg  string
+		src  string
+		want string
+	}{
+		{
+			name: "single line first",
+			list: projectDependencies(),
+			pkg:  "requests",
+			src:  "[project]\ndependencies = [\"requests\", \"rich\"]\n",
+			want: "[project]\ndependencies = [\"rich\"]\n",
+		},
+		{
+			name: "single line last",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\"requests\", \"rich\"]\n",
+			want: "[project]\ndependencies = [\"requests\"]\n",
+		},
+		{
+			name: "single line last with trailing comma",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\"requests\", \"rich\",]\n",
+			want: "[project]\ndependencies = [\"requests\",]\n",
+		},
+		{
+			name: "two on a line of a multiline array",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    \"requests\", \"rich\",\n]\n",
+			want: "[project]\ndependencies = [\n    \"requests\",\n]\n",
+		},
+		{
+			name: "single line only",
+			list: projectDependencies(),
+			pkg:  "requests",
+			src:  "[project]\ndependencies = [\"requests\"]\n",
+			want: "[project]\ndependencies = []\n",
+		},
+		{
+			name: "multiline middle",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    \"requests\",\n    \"rich\",\n    \"httpx\",\n]\n",
+			want: "[project]\ndependencies = [\n    \"requests\",\n    \"httpx\",\n]\n",
+		},
+		{
+			name: "multiline last without trailing comma",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    \"requests\",\n    \"rich\"\n]\n",
+			want: "[project]\ndependencies = [\n    \"requests\",\n]\n",
+		},
+		{
+			name: "multiline only",
+			list: projectDependencies(),
+			pkg:  "requests",
+			src:  "[project]\ndependencies = [\n    \"requests\",\n]\n",
+			want: "[project]\ndependencies = [\n]\n",
+		},
+		{
+			name: "inline comment goes with the entry",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    \"requests\",  # http\n    \"rich\",  # pretty\n]\n",
+			want: "[project]\ndependencies = [\n    \"requests\",  # http\n]\n",
+		},
+		{
+			name: "comment lines stay",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    # keep this\n    \"rich\",\n    \"requests\",\n]\n",
+			want: "[project]\ndependencies = [\n    # keep this\n    \"requests\",\n]\n",
+		},
+		{
+			name: "hash inside a string",
+			list: projectDependencies(),
+			pkg:  "rich",
+			src:  "[project]\ndependencies = [\n    \"pkg @ https://example.com/pkg.zip#sha256=abc\",\n    \"rich\",\n]\n",
+			want: "[project]\ndependencies = [\n    \"pkg @ https://example.com/pkg.zip#sha256=abc\",\n]\n",
+		},
+		{
+			name: "single quotes",
+			list: projectDependencies(),
+			pkg:  "requests",
+			src:  "[project]\ndependencies = ['requests', 'rich']\n",
+			want: "[project]\ndependencies = ['rich']\n",
+		},
+		{
+			name: "next to an include-group",
+			list: dependencyGroup("dev"),
+			pkg:  "ruff",
+			src:  "[dependency-groups]\ndev = [{include-group = \"test\"}, \"ruff\"]\n",
+			want: "[dependency-groups]\ndev = [{include-group = \"test\"}]\n",
+		},
+		{
+			name: "extra",
+			list: optionalDependency("socks"),
+			pkg:  "pysocks",
+			src:  "[project.optional-dependencies]\nsocks = [\"PySocks>=1.5.6, !=1.5.7\"]\nfast = [\"orjson\"]\n",
+			want: "[project.optional-dependencies]\nsocks = []\nfast = [\"orjson\"]\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var array, ok = findTomlArray(c.src, c.list)
+			if !ok {
+				t.Fatal("array not found")
+			}
+			var i = findDependency(array, c.pkg)
+			if i < 0 {
+				t.Fatalf("%v not found", c.pkg)
+			}
+			if got := removeFromTomlArray(c.src, array, i); got != c.want {
+				t.Errorf("got\n%v\nwant\n%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestReplaceTomlArrayItem(t *testing.T) {
+	var cases = []struct {
+		name  string
+		value string
+		src   string
+		want  string
+	}{
+		{
+			name:  "double quotes",
+			value: "requests>=2.32",
+			src:   "[project]\ndependencies = [\"requests\", \"rich\"]\n",
+			want:  "[project]\ndependencies = [\"requests>=2.32\", \"rich\"]\n",
+		},
+		{
+			name:  "single quotes kept",
+			value: "requests>=2.32",
+			src:   "[project]\ndependencies = [\n    'requests',  # http\n]\n",
+			want:  "[project]\ndependencies = [\n    'requests>=2.32',  # http\n]\n",
+		},
+		{
+			name:  "single quotes in the value",
+			value: "requests; python_version >= '3.8'",
+			src:   "[project]\ndependencies = ['requests']\n",
+			want:  "[project]\ndependencies = [\"requests; python_version >= '3.8'\"]\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var array, _ = findTomlArray(c.src, projectDependencies())
+			var i = findDependency(array, "requests")
+			if i < 0 {
+				t.Fatal("requests not found")
+			}
+			if got := replaceTomlArrayItem(c.src, array.items[i], c.value); got != c.want {
+				t.Errorf("got\n%v\nwant\n%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCreateTomlArray(t *testing.T) {
+	var cases = []struct {
+		name  string
+		list  dependencyList
+		value string
+		src   string
+		want  string
+	}{
+		{
+			name: "empty file",
+			list: dependencyGroup("dev"),
+			src:  "",
+			want: "[dependency-groups]\ndev = []\n",
+		},
+		{
+			name:  "missing table at the end",
+			list:  dependencyGroup("dev"),
+			value: "pytest",
+			src:   "[project]\nname = \"demo\"\n",
+			want:  "[project]\nname = \"demo\"\n\n[dependency-groups]\ndev = [\n    \"pytest\",\n]\n",
+		},
+		{
+			name: "missing table without a final newline",
+			list: dependencyGroup("dev"),
+			src:  "[project]\nname = \"demo\"",
+			want: "[project]\nname = \"demo\"\n\n[dependency-groups]\ndev = []\n",
+		},
+		{
+			name: "table between tables",
+			list: dependencyGroup("lint"),
+			src:  "[project]\nname = \"demo\"\n\n[dependency-groups]\ndev = [\n    \"pytest\",\n]\n\n[tool.ruff]\nline-length = 100\n",
+			want: "[project]\nname = \"demo\"\n\n[dependency-groups]\ndev = [\n    \"pytest\",\n]\nlint = []\n\n[tool.ruff]\nline-length = 100\n",
+		},
+		{
+			name: "empty table between tables",
+			list: dependencyGroup("dev"),
+			src:  "[dependency-groups]\n\n[tool.ruff]\nline-length = 100\n",
+			want: "[dependency-groups]\ndev = []\n\n[tool.ruff]\nline-length = 100\n",
+		},
+		{
+			name: "table at the end without a final newline",
+			list: dependencyGroup("lint"),
+			src:  "[dependency-groups]\ndev = [\"pytest\"]",
+			want: "[dependency-groups]\ndev = [\"pytest\"]\nlint = []\n",
+		},
+		{
+			name: "header with a comment",
+			list: dependencyGroup("lint"),
+			src:  "[dependency-groups]  # PEP 735\ndev = [\"pytest\"]\n",
+			want: "[dependency-groups]  # PEP 735\ndev = [\"pytest\"]\nlint = []\n",
+		},
+		{
+			name: "dotted key is quoted",
+			list: dependencyGroup("py3.12"),
+			src:  "[dependency-groups]\n",
+			want: "[dependency-groups]\n\"py3.12\" = []\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := createTomlArray(c.src, c.list, c.value); got != c.want {
+				t.Errorf("got\n%v\nwant\n%v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFindTomlTable(t *testing.T) {
+	var src = "[project]\nname = \"demo\"\ndependencies = [\n    \"requests\",\n    [\"not\", \"a table\"],\n]\n\n[project.optional-dependencies]\nsocks = []\n"
+	var start, end = findTomlTable(src, "project")
+	if got, want := src[start:end], "name = \"demo\"\ndependencies = [\n    \"requests\",\n    [\"not\", \"a table\"],\n]\n\n"; got != want {
+		t.Errorf("project body = %q, want %q", got, want)
+	}
+	start, end = findTomlTable(src, "project.optional-dependencies")
+	if got, want := src[start:end], "socks = []\n"; got != want {
+		t.Errorf("extras body = %q, want %q", got, want)
+	}
+	if start, _ := findTomlTable(src, "dependency-groups"); start != -1 {
+		t.Errorf("missing table start = %v, want -1", start)
+	}
+}
+
+func TestMoveDependency(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var src = "[project]\nname = \"demo\"\ndependencies = [\n    \"requests>=2\",  # http\n    'pytest>=8',\n]\n\n[tool.ruff]\nline-length = 100\n"
+	if err := os.WriteFile(pyprojectFile, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := moveDependency(projectDependencies(), dependencyGroup("dev"), "pytest"); err != nil {
+		t.Fatal(err)
+	}
+	var got, _ = readPyproject()
+	var want = "[project]\nname = \"demo\"\ndependencies = [\n    \"requests>=2\",  # http\n]\n\n[tool.ruff]\nline-length = 100\n\n[dependency-groups]\ndev = [\n    \"pytest>=8\",\n]\n"
+	if got != want {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+	if lists := listDependencyLists(got); !slices.Equal(lists, []dependencyList{projectDependencies(), dependencyGroup("dev")}) {
+		t.Errorf("lists = %v", lists)
+	}
+
+	if err := moveDependency(projectDependencies(), dependencyGroup("dev"), "httpx"); err == nil {
+		t.Error("moving a package that isn't declared should fail")
+	}
+}
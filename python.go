@@ -2,6 +2,8 @@ package main
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
@@ -9,63 +11,151 @@ import (
 	"strings"
 
 	"github.com/pelletier/go-toml/v2"
+	"lazypython/pep"
 )
 
-func generatePackageDetails() (pythonManager, error) {
+const (
+	packageSourcePip      = "pip freeze"
+	packageSourceMetadata = "site-packages"
+)
+
+func generatePackageDetails(manager packageManager, env pythonEnv, source string) (pythonManager, error) {
 	var pkgs pythonManager
-	var cmd = exec.Command("pip", "freeze")
-	var fres, err = cmd.Output()
+	pkgs.version = getPythonVersion(env.interpreter)
+	var installed, err = manager.list(env, source)
 	if err != nil {
 		return pkgs, err
 	}
-
-	var sres = strings.Split(string(fres), "\n")
+	pkgs.packages = installed
+	addInstalledLicenses(env, pkgs.packages)
 
 	var uvConfig = readTomlFile()
-	var uvPackageStrings []pythonPackage
+	var declared []requirementEntry
+	var markerEnv = pythonEnvironment(env.interpreter)
 
 	for _, pkgS := range uvConfig.Project.Dependencies {
-		var sS = strings.Split(pkgS, ">=")
-		if len(sS) > 1 {
-			uvPackageStrings = append(uvPackageStrings,
-				pythonPackage{path: strings.TrimSpace(sS[0]), version: sS[1]},
-			)
+		var req, err = pep.ParseRequirement(pkgS)
+		if err != nil {
+			continue
 		}
+		declared = append(declared, requirementEntry{requirement: req, source: "pyproject.toml"})
 	}
 
-	for _, s := range sres {
-		var split = strings.Split(s, "==")
-		if len(split) > 1 {
-			pkgs.packages = append(pkgs.packages, pythonPackage{path: split[0], version: split[1]})
-		}
-	}
+	// a broken requirements file shouldn't hide the environment, report it alongside
+	var requirements, skipped, requirementsErr = readRequirementsFiles()
+	declared = append(declared, requirements...)
+	pkgs.skippedRequirements = skipped
 
-	var checkIfPackageExists = func(pkg string) bool {
-		for _, p := range pkgs.packages {
-			if p.path == pkg {
-				return true
+	var findPackage = func(pkg string) int {
+		for i, p := range pkgs.packages {
+			if pep.Normalize(p.path) == pep.Normalize(pkg) {
+				return i
 			}
 		}
 
-		return false
+		return -1
 	}
-	for _, pkg := range uvPackageStrings {
-		if checkIfPackageExists(pkg.path) {
+	for _, entry := range declared {
+		if entry.constraint || !entry.requirement.Applies(markerEnv) {
 			continue
 		}
 
-		pkgs.packages = append(pkgs.packages, pkg)
+		var i = findPackage(entry.requirement.Name)
+		if i < 0 {
+			pkgs.packages = append(pkgs.packages, pythonPackage{path: entry.requirement.Name, version: requirementVersion(entry.requirement)})
+			i = len(pkgs.packages) - 1
+		}
+		if !containsString(pkgs.packages[i].sources, entry.source) {
+			pkgs.packages[i].sources = append(pkgs.packages[i].sources, entry.source)
+		}
 	}
 
 	pkgs.scripts = getPythonScriptsFromDisk(".")
+	pkgs.requiresPython = uvConfig.Project.RequiresPython
+
+	var lockErr error
+	pkgs.lock, lockErr = readLockFile()
 
+	return pkgs, errors.Join(requirementsErr, lockErr)
+}
+
+// the License column comes from site-packages metadata whichever way the packages were listed
+func addInstalledLicenses(env pythonEnv, pkgs []pythonPackage) {
+	var dirs, err = findSitePackages(env.interpreter)
+	if err != nil {
+		return
+	}
+	var licenses = make(map[string]string)
+	for _, dist := range readInstalledDistributions(dirs) {
+		licenses[pep.Normalize(dist.name)] = dist.license
+	}
+	for i := range pkgs {
+		if pkgs[i].license == "" {
+			pkgs[i].license = licenses[pep.Normalize(pkgs[i].path)]
+		}
+	}
+}
+
+// pip freeze is the default, reading site-packages directly is used when asked to or when pip is missing
+func listInstalledPackages(python string, source string) ([]pythonPackage, error) {
+	if source == packageSourceMetadata {
+		return listMetadataPackages(python)
+	}
+
+	var pkgs, err = listPipFreezePackages(python)
+	if err != nil {
+		// uv venvs don't ship pip at all
+		if metadataPkgs, metadataErr := listMetadataPackages(python); metadataErr == nil {
+			return metadataPkgs, nil
+		}
+	}
+	return pkgs, err
+}
+
+func listPipFreezePackages(python string) ([]pythonPackage, error) {
+	var pkgs []pythonPackage
+	var cmd = exec.Command(python, "-m", "pip", "freeze")
+	var fres, err = cmd.Output()
+	if err != nil {
+		return pkgs, err
+	}
+
+	for _, s := range strings.Split(string(fres), "\n") {
+		// editable installs and pip options aren't requirements
+		if s = strings.TrimSpace(s); s == "" || strings.HasPrefix(s, "-") || strings.HasPrefix(s, "#") {
+			continue
+		}
+		var req, err = pep.ParseRequirement(s)
+		if err != nil {
+			continue
+		}
+		pkgs = append(pkgs, pythonPackage{path: req.Name, version: requirementVersion(req), installed: true})
+	}
 	return pkgs, nil
 }
 
-func getPythonVersion() string {
-	var cmd = exec.Command("python", "--version")
+// pinned requirements show their version, anything looser shows the specifier itself
+func requirementVersion(req pep.Requirement) string {
+	switch {
+	case req.URL != "":
+		return "direct"
+	case len(req.Specifier) == 1 && req.Specifier[0].Operator == "==" && !req.Specifier[0].Wildcard:
+		return req.Specifier[0].Version
+	case len(req.Specifier) == 0:
+		return "*"
+	}
+	return req.Specifier.String()
+}
+
+func pythonEnvironment(python string) pep.Environment {
+	return pep.NewEnvironment(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(getPythonVersion(python)), "Python")))
+}
+
+func getPythonVersion(python string) string {
+	var cmd = exec.Command(python, "--version")
 
-	var output, err = cmd.Output()
+	// python 2 prints its version to stderr
+	var output, err = cmd.CombinedOutput()
 	if err != nil {
 		return "NONE"
 	}
@@ -133,14 +223,25 @@ type Config struct {
 		Readme         string
 		RequiresPython string `toml:"requires-python"`
 		Dependencies   []string
+		// extras, installed with pip install project[extra]
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
 	}
 	DependencyGroups map[string][]string `toml:"dependency-groups"`
 	Tool             struct {
-		Uv struct {
-			IndexURL   string `toml:"index-url"`
-			PythonPath string `toml:"python-path"`
-			CacheDir   string `toml:"cache-dir"`
-		}
+		Uv uvSettings
+	}
+}
+
+type uvSettings struct {
+	IndexURL      string   `toml:"index-url"`
+	ExtraIndexURL []string `toml:"extra-index-url"`
+	PythonPath    string   `toml:"python-path"`
+	CacheDir      string   `toml:"cache-dir"`
+	Index         []struct {
+		Name     string
+		URL      string
+		Default  bool
+		Explicit bool
 	}
 }
 
@@ -167,14 +268,63 @@ type InstallResponseObject struct {
 	isErr   bool
 }
 
-func runInstallCommandAndRespond(command string, pkg string) InstallResponseObject {
-	var obj InstallResponseObject
+type UninstallResponseObject struct {
+	pkg string
+	res InstallResponseObject
+}
 
-	var cmd = exec.Command(command, pkg)
+type UpgradeResponseObject struct {
+	pkg string
+	res InstallResponseObject
+}
 
-	if command == "uv" {
-		cmd = exec.Command(command, "add", pkg)
+type LockResponseObject struct {
+	manager string
+	res     InstallResponseObject
+}
+
+type SyncResponseObject struct {
+	lock string
+	res  InstallResponseObject
+}
+
+type GroupInstallResponseObject struct {
+	list dependencyList
+	res  InstallResponseObject
+}
+
+func isPreRelease(version string) bool {
+	var v, err = pep.ParseVersion(version)
+	return err == nil && v.IsPreRelease()
+}
+
+func compareVersions(a string, b string) int {
+	return pep.CompareStrings(a, b)
+}
+
+// only real versions can be outdated, declared specifiers like ">=2.0" never are
+func isNewerVersion(latest string, installed string) bool {
+	var lv, lerr = pep.ParseVersion(latest)
+	var iv, ierr = pep.ParseVersion(installed)
+	if lerr != nil || ierr != nil {
+		return false
+	}
+	return pep.Compare(lv, iv) > 0
+}
+
+// "requests[socks]>=2,<3" -> "requests", "[socks]>=2,<3"
+func splitRequirementInput(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	var i = strings.IndexAny(input, "[<>=!~;@ (")
+	if i < 0 {
+		return input, ""
 	}
+	return strings.TrimSpace(input[:i]), strings.TrimSpace(input[i:])
+}
+
+func runCommandAndRespond(cmd *exec.Cmd) InstallResponseObject {
+	var obj InstallResponseObject
+
 	var stdout, _ = cmd.StdoutPipe()
 	var stderr, _ = cmd.StderrPipe()
 
@@ -189,17 +339,15 @@ func runInstallCommandAndRespond(command string, pkg string) InstallResponseObje
 
 	if err := cmd.Wait(); err != nil {
 		obj.content = err.Error()
+		if len(errBytes) > 0 {
+			obj.content = fmt.Sprintf("%v: %v", err.Error(), strings.TrimSpace(string(errBytes)))
+		}
 		obj.isErr = true
 		return obj
 
 	}
 
-	if len(errBytes) > 0 {
-		obj.content = string(errBytes)
-		obj.isErr = true
-	} else {
-		obj.content = string(outBytes)
-
-	}
+	// uv (and pip's warnings) write progress to stderr, a zero exit status is what counts
+	obj.content = string(outBytes) + string(errBytes)
 	return obj
 }
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
@@ -45,20 +47,64 @@ func drawPythonRemotePackagesTable(m *model, pkgs []string) {
 	m.remotePackageTable.SetStyles(style)
 }
 
+func pythonPackageRows(m *model, pkgs []pythonPackage) []table.Row {
+	var rows []table.Row
+	for _, pack := range pkgs {
+		var name = pack.path
+		if m.selectedPackages[pack.path] {
+			name = "● " + name
+		}
+
+		var outdated = "?"
+		if latest, ok := m.latestVersions[pack.path]; ok {
+			outdated = ""
+			if isNewerVersion(latest, pack.version) {
+				outdated = latest
+			}
+		}
+		var installer = pack.installer
+		if pack.editable {
+			installer += " (e)"
+		}
+		// drift is marked with ! since a styled cell would break the selected row highlight
+		var locked = ""
+		if m.lockFile.found() {
+			locked = "-"
+			if version, ok := m.lockFile.version(pack.path); ok {
+				locked = version
+				if lockDrift(pack, m.lockFile) {
+					locked = "! " + version
+				}
+			}
+		}
+		rows = append(rows, table.Row{name, pack.version, locked, outdated, installer, pack.license, strings.Join(pack.sources, ", ")})
+	}
+	return rows
+}
+
+func updatePythonPackageTable(m *model) {
+	m.packageTable.SetRows(pythonPackageRows(m, m.localPackages))
+}
+
 func drawPythonPackageTable(m *model, pman pythonManager) {
+	// the frame on the home screen is half the window, each column also pads one cell on either side
 	columns := []table.Column{
-		{Title: "Package", Width: ((m.window.width + 10) / 2) / 2},
-		{Title: "Version", Width: ((m.window.width / 2) / 2) / 2},
+		{Title: "Package"},
+		{Title: "Version", Width: 10},
+		{Title: "Locked", Width: 10},
+		{Title: "Outdated", Width: 10},
+		{Title: "Installer", Width: 9},
+		{Title: "License", Width: 12},
+		{Title: "Source", Width: 16},
 	}
-
-	var rows []table.Row
-	for _, pack := range pman.packages {
-		rows = append(rows, table.Row{pack.path, pack.version})
+	columns[0].Width = m.window.width/2 - 5 - 2*len(columns) - 67
+	if columns[0].Width < 12 {
+		columns[0].Width = 12
 	}
 
 	m.packageTable = table.New(
 		table.WithColumns(columns),
-		table.WithRows(rows),
+		table.WithRows(pythonPackageRows(m, pman.packages)),
 		table.WithFocused(true),
 		table.WithWidth(m.window.width/2),
 		table.WithHeight(m.window.height/2),
@@ -109,3 +155,66 @@ func drawPythonScriptsTable(m *model, pman pythonManager) {
 		Bold(false)
 	m.pythonScriptTable.SetStyles(style)
 }
+
+func drawVersionTable(m *model, info PackageInfo) {
+	var versions []string
+	for version := range info.Releases {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+
+	columns := []table.Column{
+		{Title: "Version", Width: (m.window.width / 2 / 2) / 2},
+		{Title: "Uploaded", Width: (m.window.width / 2 / 2) / 2},
+		{Title: "Notes", Width: (m.window.width / 2 / 2) - 18},
+	}
+
+	var rows []table.Row
+	for _, version := range versions {
+		var files = info.Releases[version]
+		var uploaded string
+		var yanked = len(files) > 0
+		for _, file := range files {
+			yanked = yanked && file.Yanked
+			if uploaded == "" && len(file.UploadTime) >= 10 {
+				uploaded = file.UploadTime[:10]
+			}
+		}
+
+		var notes []string
+		if version == info.Info.Version {
+			notes = append(notes, "latest")
+		}
+		if isPreRelease(version) {
+			notes = append(notes, "pre-release")
+		}
+		if yanked {
+			notes = append(notes, "yanked")
+		}
+		if len(files) == 0 {
+			notes = append(notes, "no files")
+		}
+		rows = append(rows, table.Row{version, uploaded, strings.Join(notes, ", ")})
+	}
+
+	m.versionTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height/2-4),
+	)
+
+	style := table.DefaultStyles()
+	style.Header = style.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	style.Selected = style.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	m.versionTable.SetStyles(style)
+}
@@ -1,82 +1,144 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"strings"
-
-	"github.com/k3a/html2text"
+	"context"
+	"sync"
 )
 
-var pythonPackages []string
+// had ai grab the important args to make this struct
+type PackageInfo struct {
+	Info struct {
+		Name           string `json:"name"`
+		Version        string `json:"version"`
+		Summary        string `json:"summary"`
+		AuthorEmail    string `json:"author_email"`
+		RequiresPython string `json:"requires_python"`
+	} `json:"info"`
 
-func fetchPackagesFromIndex() {
-	var response, err = http.Get("https://pypi.org/simple/")
-	if err != nil {
-		return
-	}
+	Releases map[string][]struct {
+		Size       int    `json:"size"`
+		Yanked     bool   `json:"yanked"`
+		UploadTime string `json:"upload_time"`
+	} `json:"releases"`
 
-	defer response.Body.Close()
-	var scanner = bufio.NewScanner(response.Body)
+	// the files of the version in Info, the latest one unless a release was asked for
+	URLs []releaseFile `json:"urls"`
 
-	for scanner.Scan() {
-		var plain = html2text.HTML2Text(scanner.Text())
-		var split = strings.Split(plain, "/")
-		if len(split) > 2 {
-			pythonPackages = append(pythonPackages, split[2])
-		}
+	Downloads downloadStats `json:"downloads"`
+}
 
-	}
+type downloadStats struct {
+	LastDay   int `json:"last_day"`
+	LastWeek  int `json:"last_week"`
+	LastMonth int `json:"last_month"`
+}
 
+func getLatestVersion(ctx context.Context, client indexClient, name string) (string, error) {
+	var pkg, err = client.projectMetadata(ctx, name, "")
+	if err != nil {
+		return "", err
+	}
+	return pkg.Info.Version, nil
 }
 
-// had ai grab the important args to make this struct
-type PackageInfo struct {
-	Info struct {
-		Name        string `json:"name"`
-		Version     string `json:"version"`
-		Summary     string `json:"summary"`
-		AuthorEmail string `json:"author_email"`
-	} `json:"info"`
+// checks every installed package against the index, a few at a time so we don't hammer pypi
+func fetchLatestVersions(ctx context.Context, client indexClient, pkgs []pythonPackage) map[string]string {
+	var latest = make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var limit = make(chan struct{}, 8)
+
+	for _, pkg := range pkgs {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			var version, err = getLatestVersion(ctx, client, name)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			latest[name] = version
+			mu.Unlock()
+		}(pkg.path)
+	}
 
-	Releases map[string][]struct {
-		Size int `json:"size"`
-	} `json:"releases"`
+	wg.Wait()
+	return latest
+}
 
-	Downloads struct {
-		LastDay   int `json:"last_day"`
-		LastWeek  int `json:"last_week"`
-		LastMonth int `json:"last_month"`
-	} `json:"downloads"`
+// a file uploaded for a release, a wheel or the sdist
+type releaseFile struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+	Size     int64  `json:"size"`
+	Digests  struct {
+		Sha256 string `json:"sha256"`
+	} `json:"digests"`
 }
 
-func getPackageInfo(name string) PackageInfo {
-	var pkg PackageInfo
+// registries that only serve the simple API still list a release's files on the project page
+func getReleaseFiles(ctx context.Context, client indexClient, name string, version string) ([]releaseFile, error) {
+	var release, err = client.projectMetadata(ctx, name, version)
+	if err == nil {
+		return release.URLs, nil
+	}
+
+	var files, simpleErr = client.projectFiles(ctx, name)
+	if simpleErr != nil {
+		return nil, err
+	}
+	var urls []releaseFile
+	for _, file := range files {
+		if compareVersions(file.version(name), version) != 0 {
+			continue
+		}
+		var release = releaseFile{Filename: file.filename, URL: file.url, Size: file.size}
+		release.Digests.Sha256 = file.hashes["sha256"]
+		urls = append(urls, release)
+	}
+	if len(urls) == 0 {
+		return nil, err
+	}
+	return urls, nil
+}
 
-	var resp, err = http.Get(fmt.Sprintf("https://pypi.org/pypi/%v/json", name))
+// sha256 of every file uploaded for a release, in the form --hash expects
+func getReleaseHashes(ctx context.Context, client indexClient, name string, version string) ([]string, error) {
+	var files, err = getReleaseFiles(ctx, client, name, version)
 	if err != nil {
-		return pkg
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	json.NewDecoder(resp.Body).Decode(&pkg)
+	var hashes []string
+	for _, file := range files {
+		if file.Digests.Sha256 != "" {
+			hashes = append(hashes, "sha256:"+file.Digests.Sha256)
+		}
+	}
+	return hashes, nil
+}
 
-	var resp2, err2 = http.Get(fmt.Sprintf("https://pypistats.org/api/packages/%v/recent", name))
-	defer resp.Body.Close()
+// the project with its recent downloads, which are left at zero when pypistats can't say
+func getPackageInfo(ctx context.Context, client indexClient, name string) (PackageInfo, error) {
+	var pkg, err = client.projectMetadata(ctx, name, "")
+	if err != nil {
+		return pkg, err
+	}
 
-	var stats struct {
-		Data struct {
-			LastDay   int `json:"last_day"`
-			LastWeek  int `json:"last_week"`
-			LastMonth int `json:"last_month"`
-		} `json:"data"`
+	if stats, err := client.downloadStats(ctx, name); err == nil {
+		pkg.Downloads = stats
 	}
-	json.NewDecoder(resp2.Body).Decode(&stats)
+	return pkg, nil
+}
 
-	if err2 == nil {
-		pkg.Downloads = stats.Data
+// the version and Requires-Python of a release, the latest release when version is empty
+func getRequiresPython(ctx context.Context, client indexClient, name string, version string) (string, string, error) {
+	var release, err = client.projectMetadata(ctx, name, version)
+	if err != nil {
+		return "", "", err
 	}
-	return pkg
+	return release.Info.Version, release.Info.RequiresPython, nil
 }
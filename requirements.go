@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"lazypython/pep"
+)
+
+type requirementEntry struct {
+	requirement pep.Requirement
+	source      string
+	line        int
+	hashes      []string
+	constraint  bool
+	editable    bool
+}
+
+type requirementsFile struct {
+	entries        []requirementEntry
+	indexURL       string
+	extraIndexURLs []string
+	findLinks      []string
+	// lines that were read but left out, for the logs
+	skipped []string
+}
+
+var requirementsEnvPattern = regexp.MustCompile(`\$\{([A-Z0-9_]+)\}`)
+var eggFragmentPattern = regexp.MustCompile(`#egg=([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// the first line of every file e and E export
+const exportedRequirementsHeader = "# generated by lazypython"
+
+// every requirements file in the project root and a requirements/ folder, our own exports are
+// pins of the environment rather than anything the project declares
+func findRequirementsFiles() []string {
+	var found, _ = filepath.Glob("requirements*.txt")
+	var nested, _ = filepath.Glob(filepath.Join("requirements", "*.txt"))
+	var files []string
+	for _, path := range append(found, nested...) {
+		if !exportedRequirements(path) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func exportedRequirements(path string) bool {
+	var file, err = os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	var scanner = bufio.NewScanner(file)
+	return scanner.Scan() && strings.HasPrefix(scanner.Text(), exportedRequirementsHeader)
+}
+
+func parseRequirementsFile(path string) (requirementsFile, error) {
+	var result requirementsFile
+	var err = parseRequirementsInto(&result, path, false, make(map[string]bool))
+	return result, err
+}
+
+// -r and -c are followed relative to the including file, each file is read once so include cycles stop
+func parseRequirementsInto(result *requirementsFile, path string, constraint bool, visited map[string]bool) error {
+	var abs, _ = filepath.Abs(path)
+	if visited[abs] {
+		return nil
+	}
+	visited[abs] = true
+
+	var file, err = os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var scanner = bufio.NewScanner(file)
+	var lineNumber, startLine int
+	var pending string
+	for scanner.Scan() {
+		lineNumber++
+		var line = scanner.Text()
+		if pending == "" {
+			startLine = lineNumber
+		}
+
+		// a trailing backslash continues the line, used for long hash lists
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line = pending + line
+		pending = ""
+
+		line = stripRequirementsComment(line)
+		line = requirementsEnvPattern.ReplaceAllStringFunc(line, func(match string) string {
+			return os.Getenv(requirementsEnvPattern.FindStringSubmatch(match)[1])
+		})
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if err := parseRequirementsLine(result, path, startLine, line, constraint, visited); err != nil {
+			return fmt.Errorf("%v:%v: %w", path, startLine, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseRequirementsLine(result *requirementsFile, path string, line int, text string, constraint bool, visited map[string]bool) error {
+	var fields = strings.Fields(text)
+	var option, value = splitRequirementsOption(fields)
+	var relative = func(include string) string {
+		if filepath.IsAbs(include) || strings.Contains(include, "://") {
+			return include
+		}
+		return filepath.Join(filepath.Dir(path), include)
+	}
+
+	switch option {
+	case "-r", "--requirement":
+		return parseRequirementsInto(result, relative(value), constraint, visited)
+	case "-c", "--constraint":
+		return parseRequirementsInto(result, relative(value), true, visited)
+	case "-i", "--index-url":
+		result.indexURL = value
+		return nil
+	case "--extra-index-url":
+		result.extraIndexURLs = append(result.extraIndexURLs, value)
+		return nil
+	case "-f", "--find-links":
+		result.findLinks = append(result.findLinks, value)
+		return nil
+	case "-e", "--editable":
+		var name, err = editableName(value)
+		if err != nil {
+			result.skipped = append(result.skipped, fmt.Sprintf("%v:%v: skipped -e %v, %v", path, line, value, err))
+			return nil
+		}
+		result.entries = append(result.entries, requirementEntry{
+			requirement: pep.Requirement{Name: name, URL: value},
+			source:      path,
+			line:        line,
+			constraint:  constraint,
+			editable:    true,
+		})
+		return nil
+	case "":
+	default:
+		// --pre, --no-binary, --trusted-host and friends don't change what's declared
+		return nil
+	}
+
+	// per requirement options (--hash) come after the requirement itself
+	var requirement []string
+	var hashes []string
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "--hash="):
+			hashes = append(hashes, strings.TrimPrefix(field, "--hash="))
+		case strings.HasPrefix(field, "--"):
+		default:
+			requirement = append(requirement, field)
+		}
+	}
+
+	var req, err = pep.ParseRequirement(strings.Join(requirement, " "))
+	if err != nil {
+		return err
+	}
+	result.entries = append(result.entries, requirementEntry{
+		requirement: req,
+		source:      path,
+		line:        line,
+		hashes:      hashes,
+		constraint:  constraint,
+	})
+	return nil
+}
+
+// "-r base.txt", "--requirement=base.txt" and "-rbase.txt" are all the same thing
+func splitRequirementsOption(fields []string) (string, string) {
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "-") {
+		return "", ""
+	}
+
+	var option = fields[0]
+	if key, value, found := strings.Cut(option, "="); found {
+		return key, value
+	}
+	if len(fields) > 1 {
+		return option, fields[1]
+	}
+	if len(option) > 2 && option[1] != '-' {
+		return option[:2], option[2:]
+	}
+	return option, ""
+}
+
+// '#' only starts a comment at the beginning of a line or after whitespace, urls use it for fragments
+func stripRequirementsComment(line string) string {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return ""
+	}
+	for i := 1; i < len(line); i++ {
+		if line[i] == '#' && (line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// the project an editable install points at: #egg= when it's given, otherwise the name in the
+// local folder's pyproject.toml. pip runs relative paths from the working directory, so do we
+func editableName(value string) (string, error) {
+	if match := eggFragmentPattern.FindStringSubmatch(value); match != nil {
+		return match[1], nil
+	}
+	if strings.Contains(value, "://") {
+		return "", fmt.Errorf("a url needs #egg=<name>")
+	}
+
+	var data, err = os.ReadFile(filepath.Join(strings.TrimPrefix(value, "file:"), pyprojectFile))
+	if err != nil {
+		return "", fmt.Errorf("no %v to name it", pyprojectFile)
+	}
+	var doc struct {
+		Project struct {
+			Name string `toml:"name"`
+		} `toml:"project"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil || doc.Project.Name == "" {
+		return "", fmt.Errorf("its %v has no [project] name", pyprojectFile)
+	}
+	return doc.Project.Name, nil
+}
+
+// the entries of every requirements file, skipped holds the lines that couldn't be used
+func readRequirementsFiles() ([]requirementEntry, []string, error) {
+	var entries []requirementEntry
+	var skipped []string
+	var errs []string
+	for _, path := range findRequirementsFiles() {
+		var result, err = parseRequirementsFile(path)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		entries = append(entries, result.entries...)
+		skipped = append(skipped, result.skipped...)
+	}
+
+	if len(errs) > 0 {
+		return entries, skipped, fmt.Errorf("%v", strings.Join(errs, "; "))
+	}
+	return entries, skipped, nil
+}
+
+// pins every installed package, with --hash lines for each file of that release when asked
+func exportRequirements(ctx context.Context, client indexClient, path string, env pythonEnv, pkgs []pythonPackage, withHashes bool) error {
+	var sorted = append([]pythonPackage(nil), pkgs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return pep.Normalize(sorted[i].path) < pep.Normalize(sorted[j].path)
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v from %v\n", exportedRequirementsHeader, env.name)
+	for _, pkg := range sorted {
+		if !pkg.installed {
+			continue
+		}
+		if _, err := pep.ParseVersion(pkg.version); err != nil {
+			continue
+		}
+
+		var line = fmt.Sprintf("%v==%v", pkg.path, pkg.version)
+		if !withHashes {
+			b.WriteString(line + "\n")
+			continue
+		}
+
+		var hashes, err = getReleaseHashes(ctx, client, pkg.path, pkg.version)
+		if err != nil {
+			return fmt.Errorf("hashes for %v: %w", pkg.path, err)
+		}
+		b.WriteString(line)
+		for _, hash := range hashes {
+			b.WriteString(" \\\n    --hash=" + hash)
+		}
+		b.WriteString("\n")
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// lazypython's own per-project settings, kept out of pyproject.toml so other tools don't trip over them
+const settingsFile = ".lazypython.toml"
+
+type Settings struct {
+	Licenses licensePolicy  `toml:"licenses"`
+	Indexes  []indexSetting `toml:"index"`
+}
+
+// an empty allow list allows anything that isn't denied, ignore exempts packages by name
+type licensePolicy struct {
+	Allow  []string `toml:"allow"`
+	Deny   []string `toml:"deny"`
+	Ignore []string `toml:"ignore"`
+}
+
+// [[index]] entries, shaped like uv's. The default one replaces PyPI, the others are searched after it.
+// username and password can reference the environment, like password = "${CORP_TOKEN}"
+type indexSetting struct {
+	URL      string `toml:"url"`
+	Default  bool   `toml:"default"`
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+// a missing file is the same as an empty one
+func readSettings() (Settings, error) {
+	var settings Settings
+	var data, err = os.ReadFile(settingsFile)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	if err := toml.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("%v: %w", settingsFile, err)
+	}
+	return settings, nil
+}
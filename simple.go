@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"lazypython/pep"
+)
+
+// PEP 691 content negotiation, JSON when the index speaks it and the PEP 503 HTML pages otherwise
+const (
+	simpleJSONType = "application/vnd.pypi.simple.v1+json"
+	simpleHTMLType = "application/vnd.pypi.simple.v1+html"
+	simpleAccept   = simpleJSONType + ", " + simpleHTMLType + ";q=0.2, text/html;q=0.01"
+)
+
+// a file on a project's simple page, the same fields whichever format the index served
+type projectFile struct {
+	filename string
+	url      string
+	// hash name -> hex digest, from the JSON hashes or the URL fragment
+	hashes         map[string]string
+	requiresPython string
+	yanked         bool
+	yankedReason   string
+	// PEP 658, the file's METADATA can be fetched on its own from url + ".metadata"
+	metadata       bool
+	metadataHashes map[string]string
+	// only sent by indexes implementing PEP 700
+	size int64
+}
+
+// the version a wheel or sdist was built for, read from its filename
+func (file projectFile) version(project string) string {
+	var name = file.filename
+	for _, ext := range []string{".whl", ".tar.gz", ".tar.bz2", ".tgz", ".zip", ".egg"} {
+		if strings.HasSuffix(name, ext) {
+			name = strings.TrimSuffix(name, ext)
+			break
+		}
+	}
+	// legacy sdists keep dashes in the project name, so try every split
+	for i := range name {
+		if name[i] == '-' && pep.Normalize(name[:i]) == pep.Normalize(project) {
+			var version, _, _ = strings.Cut(name[i+1:], "-")
+			return version
+		}
+	}
+	return ""
+}
+
+// a GET for a simple API page, asking for JSON first
+func (index packageIndex) getSimple(ctx context.Context, client *httpIndexClient, pageURL string) (*http.Response, string, error) {
+	return index.getSimpleIfChanged(ctx, client, pageURL, "", "")
+}
+
+// with the validators of an earlier response, a nil response means the page hasn't changed since
+func (index packageIndex) getSimpleIfChanged(ctx context.Context, client *httpIndexClient, pageURL string, etag string, lastModified string) (*http.Response, string, error) {
+	var req, err = index.newRequest(ctx, pageURL)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", simpleAccept)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusNotModified && (etag != "" || lastModified != "") {
+		resp.Body.Close()
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", statusError(pageURL, resp)
+	}
+	var contentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return resp, contentType, nil
+}
+
+// every project name on the index's root page. cached is what the index served last time,
+// it comes back as is when the index answers 304, otherwise with the new list and validators
+func (index packageIndex) listProjects(ctx context.Context, client *httpIndexClient, cached cachedIndex) (cachedIndex, error) {
+	var resp, contentType, err = index.getSimpleIfChanged(ctx, client, index.url, cached.ETag, cached.LastModified)
+	if err != nil {
+		return cached, err
+	}
+	if resp == nil {
+		return cached, nil
+	}
+	defer resp.Body.Close()
+
+	var listing = cachedIndex{URL: index.url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if contentType == simpleJSONType {
+		var root struct {
+			Projects []struct {
+				Name string `json:"name"`
+			} `json:"projects"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+			return cached, malformedError(index.url, err)
+		}
+		for _, project := range root.Projects {
+			listing.Packages = append(listing.Packages, project.Name)
+		}
+		return listing, nil
+	}
+
+	var body, readErr = io.ReadAll(resp.Body)
+	if readErr != nil {
+		return cached, requestError(index.url, readErr)
+	}
+	for _, anchor := range parseSimpleHTML(string(body)) {
+		if name := strings.TrimSpace(anchor.text); anchor.tag == "a" && name != "" {
+			listing.Packages = append(listing.Packages, name)
+		}
+	}
+	return listing, nil
+}
+
+// the files of a project on this index, links are resolved against the page they were found on
+func (index packageIndex) projectFiles(ctx context.Context, client *httpIndexClient, name string) ([]projectFile, error) {
+	var pageURL = index.url + pep.Normalize(name) + "/"
+	var resp, contentType, err = index.getSimple(ctx, client, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var base = resp.Request.URL
+	var body, readErr = io.ReadAll(resp.Body)
+	if readErr != nil {
+		return nil, requestError(pageURL, readErr)
+	}
+	if contentType == simpleJSONType {
+		var files, err = parseSimpleJSON(body, base)
+		if err != nil {
+			return nil, malformedError(pageURL, err)
+		}
+		return files, nil
+	}
+
+	var files []projectFile
+	var anchors = parseSimpleHTML(string(body))
+	for _, anchor := range anchors {
+		if anchor.tag == "base" {
+			if href, err := base.Parse(anchor.attrs["href"]); err == nil {
+				base = href
+			}
+		}
+	}
+	for _, anchor := range anchors {
+		if anchor.tag != "a" || anchor.attrs["href"] == "" {
+			continue
+		}
+		var file = projectFile{filename: strings.TrimSpace(anchor.text), hashes: map[string]string{}, requiresPython: anchor.attrs["data-requires-python"]}
+		if href, err := base.Parse(anchor.attrs["href"]); err == nil {
+			if algorithm, digest, ok := strings.Cut(href.Fragment, "="); ok {
+				file.hashes[algorithm] = digest
+			}
+			href.Fragment = ""
+			file.url = href.String()
+		}
+		if reason, ok := anchor.attrs["data-yanked"]; ok {
+			file.yanked, file.yankedReason = true, reason
+		}
+		// PEP 714 renamed data-dist-info-metadata, older indexes only send the old name
+		var metadata, ok = anchor.attrs["data-core-metadata"]
+		if !ok {
+			metadata, ok = anchor.attrs["data-dist-info-metadata"]
+		}
+		if ok && metadata != "false" {
+			file.metadata = true
+			if algorithm, digest, found := strings.Cut(metadata, "="); found {
+				file.metadataHashes = map[string]string{algorithm: digest}
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func parseSimpleJSON(body []byte, base *url.URL) ([]projectFile, error) {
+	var page struct {
+		Files []struct {
+			Filename       string            `json:"filename"`
+			URL            string            `json:"url"`
+			Hashes         map[string]string `json:"hashes"`
+			RequiresPython string            `json:"requires-python"`
+			Size           int64             `json:"size"`
+			// false, true or the reason it was yanked
+			Yanked any `json:"yanked"`
+			// false, true or the metadata file's hashes
+			CoreMetadata     any `json:"core-metadata"`
+			DistInfoMetadata any `json:"dist-info-metadata"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, err
+	}
+
+	var files []projectFile
+	for _, entry := range page.Files {
+		var file = projectFile{filename: entry.Filename, url: entry.URL, hashes: entry.Hashes, requiresPython: entry.RequiresPython, size: entry.Size}
+		if href, err := base.Parse(entry.URL); err == nil {
+			file.url = href.String()
+		}
+		switch yanked := entry.Yanked.(type) {
+		case bool:
+			file.yanked = yanked
+		case string:
+			file.yanked, file.yankedReason = true, yanked
+		}
+		var metadata = entry.CoreMetadata
+		if metadata == nil {
+			metadata = entry.DistInfoMetadata
+		}
+		switch metadata := metadata.(type) {
+		case bool:
+			file.metadata = metadata
+		case map[string]any:
+			file.metadata = true
+			file.metadataHashes = make(map[string]string)
+			for algorithm, digest := range metadata {
+				file.metadataHashes[algorithm] = fmt.Sprint(digest)
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// an <a> or <base> tag of a PEP 503 page, the format is simple enough not to need a full HTML parser
+type simpleAnchor struct {
+	tag   string
+	attrs map[string]string
+	text  string
+}
+
+func parseSimpleHTML(page string) []simpleAnchor {
+	var anchors []simpleAnchor
+	// only ASCII is lowered so offsets into lower stay valid in page
+	var lower = []byte(page)
+	for i, c := range lower {
+		if 'A' <= c && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+	for i := 0; i < len(page); {
+		var start = strings.IndexByte(page[i:], '<')
+		if start < 0 {
+			break
+		}
+		start += i
+		var end = tagEnd(page, start)
+		if end < 0 {
+			break
+		}
+		i = end + 1
+
+		var tag, attrs = parseTag(page[start+1 : end])
+		if tag != "a" && tag != "base" {
+			continue
+		}
+		var anchor = simpleAnchor{tag: tag, attrs: attrs}
+		if tag == "a" {
+			var close = bytes.Index(lower[i:], []byte("</a"))
+			if close < 0 {
+				close = len(page) - i
+			}
+			anchor.text = html.UnescapeString(page[i : i+close])
+			i += close
+		}
+		anchors = append(anchors, anchor)
+	}
+	return anchors
+}
+
+// the '>' closing the tag opened at start, -1 when there isn't one. A quoted attribute value
+// can hold a '>' of its own, data-requires-python=">=3.8" often does
+func tagEnd(page string, start int) int {
+	var afterEquals bool
+	for i := start + 1; i < len(page); i++ {
+		switch c := page[i]; {
+		case c == '>':
+			return i
+		case (c == '"' || c == '\'') && afterEquals:
+			var close = strings.IndexByte(page[i+1:], c)
+			if close < 0 {
+				return -1
+			}
+			i += close + 1
+			afterEquals = false
+		case c == '=':
+			afterEquals = true
+		case c != ' ' && c != '\t' && c != '\r' && c != '\n':
+			afterEquals = false
+		}
+	}
+	return -1
+}
+
+// `a href="x" data-yanked` -> "a", {href: x, data-yanked: ""}. A '/' only closes the tag
+// on its own, an unquoted href=/simple/foo/ keeps its slash
+func parseTag(content string) (string, map[string]string) {
+	content = strings.TrimSpace(content)
+	var nameEnd = strings.IndexAny(content, "/ \t\r\n")
+	if nameEnd < 0 {
+		return strings.ToLower(content), map[string]string{}
+	}
+
+	var tag = strings.ToLower(content[:nameEnd])
+	var attrs = make(map[string]string)
+	var rest = content[nameEnd:]
+	for {
+		rest = strings.TrimLeft(rest, "/ \t\r\n")
+		if rest == "" {
+			break
+		}
+		var keyEnd = strings.IndexAny(rest, "=/ \t\r\n")
+		if keyEnd < 0 {
+			attrs[strings.ToLower(rest)] = ""
+			break
+		}
+		var key = strings.ToLower(rest[:keyEnd])
+		rest = strings.TrimLeft(rest[keyEnd:], " \t\r\n")
+		if !strings.HasPrefix(rest, "=") {
+			attrs[key] = ""
+			continue
+		}
+		rest = strings.TrimLeft(rest[1:], " \t\r\n")
+
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			var close = strings.IndexByte(rest[1:], rest[0])
+			if close < 0 {
+				close = len(rest) - 1
+			}
+			value, rest = rest[1:close+1], rest[min(close+2, len(rest)):]
+		} else {
+			var valueEnd = strings.IndexAny(rest, " \t\r\n")
+			if valueEnd < 0 {
+				valueEnd = len(rest)
+			}
+			value, rest = rest[:valueEnd], rest[valueEnd:]
+		}
+		attrs[key] = html.UnescapeString(value)
+	}
+	return tag, attrs
+}
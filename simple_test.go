@@ -0,0 +1,58 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseSimpleHTML(t *testing.T) {
+	var cases = []struct {
+		name string
+		page string
+		want []simpleAnchor
+	}{
+		{
+			name: "escaped attributes",
+			page: `<a href="../../files/demo-1.0.tar.gz#sha256=ab" data-requires-python="&gt;=3.8">demo-1.0.tar.gz</a>`,
+			want: []simpleAnchor{{tag: "a", attrs: map[string]string{"href": "../../files/demo-1.0.tar.gz#sha256=ab", "data-requires-python": ">=3.8"}, text: "demo-1.0.tar.gz"}},
+		},
+		{
+			name: "unescaped > in a quoted value",
+			page: `<a href="/files/demo-1.0.tar.gz" data-requires-python=">=3.8, <4">demo-1.0.tar.gz</a><br/>`,
+			want: []simpleAnchor{{tag: "a", attrs: map[string]string{"href": "/files/demo-1.0.tar.gz", "data-requires-python": ">=3.8, <4"}, text: "demo-1.0.tar.gz"}},
+		},
+		{
+			name: "single quoted value",
+			page: `<A HREF='/files/demo-1.0.tar.gz' data-requires-python='>3.7' data-yanked>demo-1.0.tar.gz</A>`,
+			want: []simpleAnchor{{tag: "a", attrs: map[string]string{"href": "/files/demo-1.0.tar.gz", "data-requires-python": ">3.7", "data-yanked": ""}, text: "demo-1.0.tar.gz"}},
+		},
+		{
+			name: "unquoted href keeps its trailing slash",
+			page: `<a href=/simple/foo/>foo</a>`,
+			want: []simpleAnchor{{tag: "a", attrs: map[string]string{"href": "/simple/foo/"}, text: "foo"}},
+		},
+		{
+			name: "self closing base",
+			page: `<base href="https://mirror.example/simple/demo/" />`,
+			want: []simpleAnchor{{tag: "base", attrs: map[string]string{"href": "https://mirror.example/simple/demo/"}}},
+		},
+		{
+			name: "other tags are skipped",
+			page: "<!DOCTYPE html>\n<html><head><meta name=\"pypi:repository-version\" content=\"1.0\"></head>\n<body><h1>Links</h1><a href=\"/simple/bar/\">bar</a></body></html>",
+			want: []simpleAnchor{{tag: "a", attrs: map[string]string{"href": "/simple/bar/"}, text: "bar"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got = parseSimpleHTML(c.page)
+			if len(got) != len(c.want) {
+				t.Fatalf("anchors = %+v, want %+v", got, c.want)
+			}
+			for i := range got {
+				if got[i].tag != c.want[i].tag || got[i].text != c.want[i].text || !maps.Equal(got[i].attrs, c.want[i].attrs) {
+					t.Errorf("anchor %v = %+v, want %+v", i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// a CLI installed into its own environment by pipx or uv tool
+type pythonTool struct {
+	name    string
+	version string
+	via     string
+	apps    []string
+}
+
+const (
+	toolBackendUv   = "uv tool"
+	toolBackendPipx = "pipx"
+)
+
+func toolBackends() []string {
+	var backends []string
+	if onPath("uv") {
+		backends = append(backends, toolBackendUv)
+	}
+	if onPath("pipx") {
+		backends = append(backends, toolBackendPipx)
+	}
+	return backends
+}
+
+// tools from every backend that's installed, a backend that fails doesn't hide the others
+func listTools() ([]pythonTool, error) {
+	var tools []pythonTool
+	var errs []error
+	for _, backend := range toolBackends() {
+		var listed, err = listBackendTools(backend)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", backend, err))
+		}
+		tools = append(tools, listed...)
+	}
+
+	sort.SliceStable(tools, func(i, j int) bool {
+		return strings.ToLower(tools[i].name) < strings.ToLower(tools[j].name)
+	})
+	return tools, errors.Join(errs...)
+}
+
+func listBackendTools(backend string) ([]pythonTool, error) {
+	if backend == toolBackendPipx {
+		var output, err = exec.Command("pipx", "list", "--json").Output()
+		if err != nil {
+			return nil, err
+		}
+		return parsePipxList(output)
+	}
+
+	var output, err = exec.Command("uv", "tool", "list").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseUvToolList(string(output)), nil
+}
+
+func parsePipxList(output []byte) ([]pythonTool, error) {
+	var listed struct {
+		Venvs map[string]struct {
+			Metadata struct {
+				MainPackage struct {
+					Package        string   `json:"package"`
+					PackageVersion string   `json:"package_version"`
+					Apps           []string `json:"apps"`
+				} `json:"main_package"`
+			} `json:"metadata"`
+		} `json:"venvs"`
+	}
+	if err := json.Unmarshal(output, &listed); err != nil {
+		return nil, err
+	}
+
+	var tools []pythonTool
+	for name, venv := range listed.Venvs {
+		var pkg = venv.Metadata.MainPackage
+		if pkg.Package != "" {
+			name = pkg.Package
+		}
+		tools = append(tools, pythonTool{name: name, version: pkg.PackageVersion, via: toolBackendPipx, apps: pkg.Apps})
+	}
+	return tools, nil
+}
+
+// "black v24.1.0" starts a tool, the "- black" lines under it are its entry points
+func parseUvToolList(output string) []pythonTool {
+	var tools []pythonTool
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "No tools installed"):
+		case strings.HasPrefix(line, "- "):
+			if len(tools) > 0 {
+				tools[len(tools)-1].apps = append(tools[len(tools)-1].apps, strings.TrimPrefix(line, "- "))
+			}
+		default:
+			var fields = strings.Fields(line)
+			var tool = pythonTool{name: fields[0], via: toolBackendUv}
+			if len(fields) > 1 {
+				tool.version = strings.TrimPrefix(fields[1], "v")
+			}
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// install, upgrade and uninstall are spelled the same by both backends
+func runToolCommandAndRespond(backend string, action string, pkg string) InstallResponseObject {
+	if backend == toolBackendPipx {
+		return runCommandAndRespond(withIndexEnv(exec.Command("pipx", action, pkg)))
+	}
+	return runCommandAndRespond(withIndexEnv(exec.Command("uv", "tool", action, pkg)))
+}
+
+// uvx / pipx run with the terminal handed over, args is the package followed by whatever it should get
+func runOnceCommand(backend string, args []string) *exec.Cmd {
+	if backend == toolBackendPipx {
+		return withIndexEnv(exec.Command("pipx", append([]string{"run"}, args...)...))
+	}
+	return withIndexEnv(exec.Command("uvx", args...))
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type ToolsLoadedMsg struct {
+	tools []pythonTool
+	err   error
+}
+
+type ToolResponseObject struct {
+	action string
+	tool   string
+	res    InstallResponseObject
+}
+
+type ToolRunFinishedMsg struct {
+	command string
+	err     error
+}
+
+func loadToolsAsync() tea.Cmd {
+	return func() tea.Msg {
+		var tools, err = listTools()
+		return ToolsLoadedMsg{tools: tools, err: err}
+	}
+}
+
+// the backend new tools go through, uv when it's there
+func defaultToolBackend() string {
+	if backends := toolBackends(); len(backends) > 0 {
+		return backends[0]
+	}
+	return ""
+}
+
+func runToolCommandAndRespondAsync(m *model, action string, backend string, pkg string) tea.Cmd {
+	m.info = fmt.Sprintf("%v Running %v %v %v...", m.spinner.View(), backend, action, pkg)
+	return tea.Sequence(func() tea.Msg {
+		return ToolResponseObject{action: action, tool: pkg, res: runToolCommandAndRespond(backend, action, pkg)}
+	}, loadToolsAsync())
+}
+
+// hands the terminal to uvx / pipx run, the first word is the package and the rest its arguments
+func runToolOnce(m *model, backend string, input string) tea.Cmd {
+	var args = strings.Fields(input)
+	if len(args) == 0 {
+		return nil
+	}
+	if backend == "" {
+		m.info = "Neither uv nor pipx is installed"
+		return nil
+	}
+	var command = strings.Join(args, " ")
+	return tea.ExecProcess(runOnceCommand(backend, args), func(err error) tea.Msg {
+		return ToolRunFinishedMsg{command: command, err: err}
+	})
+}
+
+func openRunOncePrompt(m *model, pkg string) {
+	var backend = m.toolBackend
+	if backend == "" {
+		backend = defaultToolBackend()
+	}
+	var runner = "uvx"
+	if backend == toolBackendPipx {
+		runner = "pipx run"
+	}
+	openPromptDialog(m, fmt.Sprintf("Run once with %v (package, then its arguments)", runner), pkg, func(m *model, value string) tea.Cmd {
+		return runToolOnce(m, backend, value)
+	})
+}
+
+func openToolsScreen(m *model) tea.Cmd {
+	if m.toolBackend == "" {
+		m.toolBackend = defaultToolBackend()
+	}
+	m.tools = nil
+	m.toolsLoading = true
+	drawToolsTable(m)
+	m.showToolsScreen = true
+	m.showHomeScreen = false
+	return loadToolsAsync()
+}
+
+func drawToolsTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Tool", Width: m.window.width / 4},
+		{Title: "Version", Width: 12},
+		{Title: "Via", Width: 8},
+		{Title: "Entry Points", Width: m.window.width/2 + m.window.width/4 - 32},
+	}
+
+	var rows []table.Row
+	for _, tool := range m.tools {
+		rows = append(rows, table.Row{tool.name, tool.version, tool.via, strings.Join(tool.apps, ", ")})
+	}
+
+	var cursor = m.toolsTable.Cursor()
+	m.toolsTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+	if cursor > 0 && cursor < len(rows) {
+		m.toolsTable.SetCursor(cursor)
+	}
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.toolsTable.SetStyles(s)
+}
+
+func selectedTool(m *model) (pythonTool, bool) {
+	var cursor = m.toolsTable.Cursor()
+	if cursor < 0 || cursor >= len(m.tools) {
+		return pythonTool{}, false
+	}
+	return m.tools[cursor], true
+}
+
+func updateToolsScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.showToolsScreen = false
+		m.showHomeScreen = true
+		return nil
+
+	case "i":
+		if m.toolBackend == "" {
+			m.info = "Neither uv nor pipx is installed"
+			return nil
+		}
+		var backend = m.toolBackend
+		openPromptDialog(m, fmt.Sprintf("Install a tool with %v", backend), "", func(m *model, value string) tea.Cmd {
+			if value = strings.TrimSpace(value); value == "" {
+				return nil
+			}
+			return runToolCommandAndRespondAsync(m, "install", backend, value)
+		})
+		return nil
+
+	case "U":
+		if tool, ok := selectedTool(m); ok {
+			openConfirmDialog(m, fmt.Sprintf("Upgrade %v using %v?", tool.name, tool.via), func(m *model) tea.Cmd {
+				return runToolCommandAndRespondAsync(m, "upgrade", tool.via, tool.name)
+			})
+		}
+		return nil
+
+	case "x":
+		if tool, ok := selectedTool(m); ok {
+			openConfirmDialog(m, fmt.Sprintf("Uninstall %v using %v?", tool.name, tool.via), func(m *model) tea.Cmd {
+				return runToolCommandAndRespondAsync(m, "uninstall", tool.via, tool.name)
+			})
+		}
+		return nil
+
+	case "r":
+		var name string
+		if tool, ok := selectedTool(m); ok {
+			name = tool.name
+		}
+		openRunOncePrompt(m, name)
+		return nil
+
+	case "m":
+		var backends = toolBackends()
+		for i, backend := range backends {
+			if backend == m.toolBackend {
+				m.toolBackend = backends[(i+1)%len(backends)]
+				break
+			}
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.toolsTable, cmd = m.toolsTable.Update(msg)
+	return cmd
+}
+
+func drawToolsScreen(m *model) string {
+	var backend = m.toolBackend
+	if backend == "" {
+		backend = "none found"
+	}
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Tools (installing with %v)", backend))
+
+	var body = m.toolsTable.View()
+	switch {
+	case m.toolsLoading:
+		body = fmt.Sprintf("%v Listing tools...", m.spinner.View())
+	case len(m.tools) == 0:
+		body = "No tools installed with uv tool or pipx"
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • i: install • U: upgrade • x: uninstall • r: run once • m: switch uv tool / pipx • Esc: Home\n" + m.info)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
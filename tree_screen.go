@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type treeView struct {
+	graph     dependencyGraph
+	expanded  map[string]bool
+	cursor    int
+	offset    int
+	reverseOf string
+	loading   bool
+}
+
+type treeLine struct {
+	key         string
+	path        string
+	depth       int
+	hasChildren bool
+	cycle       bool
+}
+
+type DependencyGraphMsg struct {
+	graph dependencyGraph
+	err   error
+}
+
+func loadDependencyGraphAsync(m *model) tea.Cmd {
+	var env = m.activeEnv
+	return func() tea.Msg {
+		var graph, err = loadDependencyGraph(env)
+		return DependencyGraphMsg{graph: graph, err: err}
+	}
+}
+
+// in reverse mode the children of a node are the packages that require it
+func (t *treeView) children(key string) []string {
+	var node = t.graph.nodes[key]
+	if node == nil {
+		return nil
+	}
+	if t.reverseOf != "" {
+		return node.requiredBy
+	}
+	return node.requires
+}
+
+// flattens whatever is expanded into the lines currently on screen, expansion is tracked per path
+// so the same package can be open in one place and closed in another
+func (t *treeView) lines() []treeLine {
+	var lines []treeLine
+	var walk func(key string, path string, depth int, seen map[string]bool)
+	walk = func(key string, path string, depth int, seen map[string]bool) {
+		var children = t.children(key)
+		var line = treeLine{key: key, path: path, depth: depth, hasChildren: len(children) > 0, cycle: seen[key]}
+		lines = append(lines, line)
+		if line.cycle || !t.expanded[path] {
+			return
+		}
+
+		seen[key] = true
+		for _, child := range children {
+			walk(child, path+"/"+child, depth+1, seen)
+		}
+		delete(seen, key)
+	}
+
+	var roots = t.graph.roots
+	if t.reverseOf != "" {
+		roots = []string{t.reverseOf}
+	}
+	for _, root := range roots {
+		walk(root, root, 0, make(map[string]bool))
+	}
+	return lines
+}
+
+func updateTreeScreen(m *model, msg tea.KeyMsg) tea.Cmd {
+	var t = &m.depTree
+	if t.loading {
+		if msg.String() == "esc" {
+			m.showTreeScreen = false
+			m.showHomeScreen = true
+		}
+		return nil
+	}
+
+	var lines = t.lines()
+	var pageSize = treePageSize(m)
+
+	switch msg.String() {
+	case "esc":
+		if t.reverseOf != "" {
+			t.reverseOf = ""
+			t.cursor, t.offset = 0, 0
+			return nil
+		}
+		m.showTreeScreen = false
+		m.showHomeScreen = true
+		return nil
+	case "up", "k":
+		t.cursor--
+	case "down", "j":
+		t.cursor++
+	case "pgup", "b":
+		t.cursor -= pageSize
+	case "pgdown", "f":
+		t.cursor += pageSize
+	case "g", "home":
+		t.cursor = 0
+	case "G", "end":
+		t.cursor = len(lines) - 1
+	case "enter", " ":
+		if t.cursor < len(lines) && lines[t.cursor].hasChildren {
+			t.expanded[lines[t.cursor].path] = !t.expanded[lines[t.cursor].path]
+		}
+	case "right", "l":
+		if t.cursor < len(lines) {
+			t.expanded[lines[t.cursor].path] = true
+		}
+	case "left", "h":
+		if t.cursor < len(lines) {
+			t.expanded[lines[t.cursor].path] = false
+		}
+	case "r":
+		if t.cursor < len(lines) {
+			t.reverseOf = lines[t.cursor].key
+			t.expanded[t.reverseOf] = true
+			t.cursor, t.offset = 0, 0
+		}
+	}
+
+	lines = t.lines()
+	if t.cursor >= len(lines) {
+		t.cursor = len(lines) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+pageSize {
+		t.offset = t.cursor - pageSize + 1
+	}
+	return nil
+}
+
+func treePageSize(m *model) int {
+	if size := m.window.height - 8; size > 1 {
+		return size
+	}
+	return 1
+}
+
+func drawTreeScreen(m *model) string {
+	var t = &m.depTree
+
+	var title = "Dependency Tree"
+	if t.reverseOf != "" {
+		title = fmt.Sprintf("Reverse dependencies of %v", t.graph.nodes[t.reverseOf].name)
+	}
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(title)
+
+	var declaredStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2"))
+	var orphanStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	var missingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	var dimStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	var selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+
+	var body string
+	switch {
+	case t.loading:
+		body = fmt.Sprintf("%v Reading installed metadata...", m.spinner.View())
+	case len(t.graph.nodes) == 0:
+		body = "No installed packages found"
+	default:
+		var lines = t.lines()
+		var rendered []string
+		for i := t.offset; i < len(lines) && i < t.offset+treePageSize(m); i++ {
+			var line = lines[i]
+			var node = t.graph.nodes[line.key]
+
+			var marker = "  "
+			if line.hasChildren && !line.cycle {
+				marker = "▸ "
+				if t.expanded[line.path] {
+					marker = "▾ "
+				}
+			}
+
+			var name = node.name
+			if node.declared {
+				name = declaredStyle.Render(name)
+			}
+			var text = strings.Repeat("  ", line.depth) + marker + name + " " + dimStyle.Render(node.version)
+
+			switch {
+			case node.missing:
+				text += " " + missingStyle.Render("(missing)")
+			case line.cycle:
+				text += " " + dimStyle.Render("(cycle)")
+			case t.graph.isOrphan(line.key):
+				text += " " + orphanStyle.Render("orphan")
+			}
+
+			if i == t.cursor {
+				text = selectedStyle.Render(text)
+			}
+			rendered = append(rendered, text)
+		}
+		body = strings.Join(rendered, "\n")
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • Enter/Space: expand/collapse • r: reverse dependencies • " +
+			declaredStyle.Render("declared") + " " + orphanStyle.Render("orphan") + " • Esc: back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
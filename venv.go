@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"lazypython/pep"
+)
+
+type pythonEnv struct {
+	name        string
+	kind        string
+	path        string
+	interpreter string
+}
+
+const (
+	envKindSystem = "system"
+	envKindVenv   = "venv"
+	envKindPyenv  = "pyenv"
+	envKindUv     = "uv"
+	envKindConda  = "conda"
+)
+
+var systemPythonEnv = pythonEnv{name: "python (PATH)", kind: envKindSystem, interpreter: "python"}
+
+// the python binary inside an environment root, venvs and conda lay these out differently on windows
+func envInterpreter(root string) string {
+	var candidates = []string{
+		filepath.Join(root, "bin", "python"),
+		filepath.Join(root, "bin", "python3"),
+		filepath.Join(root, "Scripts", "python.exe"),
+		filepath.Join(root, "python.exe"),
+	}
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func newPythonEnv(name string, kind string, root string) (pythonEnv, bool) {
+	var interpreter = envInterpreter(root)
+	if interpreter == "" {
+		return pythonEnv{}, false
+	}
+	var abs, err = filepath.Abs(root)
+	if err == nil {
+		root = abs
+	}
+	return pythonEnv{name: name, kind: kind, path: root, interpreter: interpreter}, true
+}
+
+// an activated venv wins, then a project venv, then whatever python is on PATH
+func defaultPythonEnv() pythonEnv {
+	if virtualEnv := os.Getenv("VIRTUAL_ENV"); virtualEnv != "" {
+		if env, ok := newPythonEnv(filepath.Base(virtualEnv)+" ($VIRTUAL_ENV)", envKindVenv, virtualEnv); ok {
+			return env
+		}
+	}
+	for _, dir := range []string{".venv", "venv"} {
+		if env, ok := newPythonEnv(dir, envKindVenv, dir); ok {
+			return env
+		}
+	}
+	return systemPythonEnv
+}
+
+func discoverEnvironments() []pythonEnv {
+	var envs []pythonEnv
+	var seen = make(map[string]bool)
+	var add = func(env pythonEnv, ok bool) {
+		if !ok || seen[env.interpreter] {
+			return
+		}
+		seen[env.interpreter] = true
+		envs = append(envs, env)
+	}
+
+	if path, err := exec.LookPath("python"); err == nil {
+		var env = systemPythonEnv
+		env.path = filepath.Dir(path)
+		add(env, true)
+	}
+
+	if virtualEnv := os.Getenv("VIRTUAL_ENV"); virtualEnv != "" {
+		add(newPythonEnv(filepath.Base(virtualEnv)+" ($VIRTUAL_ENV)", envKindVenv, virtualEnv))
+	}
+	for _, dir := range []string{".venv", "venv"} {
+		add(newPythonEnv(dir, envKindVenv, dir))
+	}
+
+	var home, _ = os.UserHomeDir()
+
+	var pyenvRoot = os.Getenv("PYENV_ROOT")
+	if pyenvRoot == "" {
+		pyenvRoot = filepath.Join(home, ".pyenv")
+	}
+	for _, dir := range subdirectories(filepath.Join(pyenvRoot, "versions")) {
+		add(newPythonEnv("pyenv "+filepath.Base(dir), envKindPyenv, dir))
+	}
+
+	for _, dir := range subdirectories(uvPythonDir(home)) {
+		add(newPythonEnv("uv "+filepath.Base(dir), envKindUv, dir))
+	}
+
+	for _, dir := range condaEnvironments(home) {
+		add(newPythonEnv("conda "+filepath.Base(dir), envKindConda, dir))
+	}
+
+	return envs
+}
+
+func uvPythonDir(home string) string {
+	if dir := os.Getenv("UV_PYTHON_INSTALL_DIR"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "uv", "python")
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "uv", "python")
+	}
+	return filepath.Join(home, ".local", "share", "uv", "python")
+}
+
+// conda keeps a registry of every env it created, the usual install roots cover the rest
+func condaEnvironments(home string) []string {
+	var dirs []string
+
+	if prefix := os.Getenv("CONDA_PREFIX"); prefix != "" {
+		dirs = append(dirs, prefix)
+	}
+
+	if file, err := os.Open(filepath.Join(home, ".conda", "environments.txt")); err == nil {
+		var scanner = bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				dirs = append(dirs, line)
+			}
+		}
+		file.Close()
+	}
+
+	for _, base := range []string{"miniconda3", "miniconda", "anaconda3", "miniforge3", "mambaforge"} {
+		var root = filepath.Join(home, base)
+		if dirExists(root) {
+			dirs = append(dirs, root)
+			dirs = append(dirs, subdirectories(filepath.Join(root, "envs"))...)
+		}
+	}
+
+	return dirs
+}
+
+func subdirectories(path string) []string {
+	var entries, err = os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(path, entry.Name()))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+type venvOptions struct {
+	path        string
+	tool        string
+	python      string
+	seedPip     bool
+	installFrom string
+}
+
+// version strings like "3.12" are looked up on PATH and among discovered interpreters,
+// anything else is passed through as a path or command name
+func resolveInterpreter(value string, envs []pythonEnv) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "python"
+	}
+	if _, err := os.Stat(value); err == nil {
+		return value
+	}
+	if _, err := pep.ParseVersion(value); err != nil {
+		return value
+	}
+
+	// shims like pyenv's put python3.x on PATH even when it can't run, so real interpreters go first
+	for _, env := range envs {
+		var version = strings.TrimPrefix(strings.TrimSpace(getPythonVersion(env.interpreter)), "Python ")
+		if version == value || strings.HasPrefix(version, value+".") {
+			return env.interpreter
+		}
+	}
+	if path, err := exec.LookPath("python" + value); err == nil {
+		return path
+	}
+	return value
+}
+
+func createVirtualEnv(opts venvOptions, envs []pythonEnv) (pythonEnv, InstallResponseObject) {
+	var cmd *exec.Cmd
+	if opts.tool == "uv" {
+		var args = []string{"venv"}
+		if opts.python != "" {
+			// uv resolves (and downloads) versions itself
+			args = append(args, "--python", opts.python)
+		}
+		if opts.seedPip {
+			args = append(args, "--seed")
+		}
+		// --seed fetches pip from the index
+		cmd = withIndexEnv(exec.Command("uv", append(args, opts.path)...))
+	} else {
+		var args = []string{"-m", "venv"}
+		// the install source goes in with the environment's own pip, so it needs one
+		if !opts.seedPip && opts.installFrom == "" {
+			args = append(args, "--without-pip")
+		}
+		cmd = exec.Command(resolveInterpreter(opts.python, envs), append(args, opts.path)...)
+	}
+
+	var res = runCommandAndRespond(cmd)
+	if res.isErr {
+		return pythonEnv{}, res
+	}
+
+	var env, ok = newPythonEnv(filepath.Base(opts.path), envKindVenv, opts.path)
+	if !ok {
+		return env, InstallResponseObject{content: "environment was created but no interpreter was found in " + opts.path, isErr: true}
+	}
+
+	if opts.installFrom == "" {
+		return env, res
+	}
+
+	var source = opts.installFrom
+	if source == "pyproject.toml" && opts.tool != "uv" {
+		// pip can't read dependencies out of pyproject directly, install the project instead
+		source = "."
+	}
+
+	var install *exec.Cmd
+	switch {
+	case opts.tool == "uv":
+		install = exec.Command("uv", "pip", "install", "--python", env.interpreter, "-r", source)
+	case source == ".":
+		install = exec.Command(env.interpreter, "-m", "pip", "install", source)
+	default:
+		install = exec.Command(env.interpreter, "-m", "pip", "install", "-r", source)
+	}
+
+	var installRes = runCommandAndRespond(withIndexEnv(install))
+	installRes.content = res.content + installRes.content
+	return env, installRes
+}
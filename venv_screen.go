@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func drawVenvTable(m *model) {
+	var columns = []table.Column{
+		{Title: "Environment", Width: m.window.width / 4},
+		{Title: "Kind", Width: 8},
+		{Title: "Interpreter", Width: m.window.width/2 + m.window.width/4 - 20},
+	}
+
+	var rows []table.Row
+	for _, env := range m.environments {
+		var name = env.name
+		if env.interpreter == m.activeEnv.interpreter {
+			name = "● " + name
+		}
+		rows = append(rows, table.Row{name, env.kind, env.interpreter})
+	}
+
+	m.venvTable = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(m.window.height-8),
+	)
+
+	var s = table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+
+	m.venvTable.SetStyles(s)
+}
+
+func drawVenvScreen(m *model) string {
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render(fmt.Sprintf("Python Environments (active: %v)", m.activeEnv.name))
+
+	var body = m.venvTable.View()
+	if len(m.environments) == 0 {
+		body = "No python environments found"
+	}
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("j/k: navigate • Enter: activate • n: new environment • Esc: Home")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		body,
+		footer,
+	)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	wizardFieldPath = iota
+	wizardFieldTool
+	wizardFieldPython
+	wizardFieldSeed
+	wizardFieldInstall
+	wizardFieldCount
+)
+
+type venvWizard struct {
+	field        int
+	pathInput    textinput.Model
+	pythonInput  textinput.Model
+	tool         string
+	seedPip      bool
+	installFrom  string
+	installFroms []string
+	creating     bool
+}
+
+type VenvCreatedMsg struct {
+	env pythonEnv
+	res InstallResponseObject
+}
+
+func newVenvWizard(m *model) venvWizard {
+	var pathInput = textinput.New()
+	pathInput.SetValue(".venv")
+	pathInput.CharLimit = -1
+	pathInput.Focus()
+
+	var pythonInput = textinput.New()
+	pythonInput.CharLimit = -1
+	pythonInput.Placeholder = "3.12, python3.11 or a path"
+	pythonInput.SetValue(m.activeEnv.interpreter)
+
+	// only offer the manifests this project actually has
+	var installFroms = []string{""}
+	for _, file := range []string{"pyproject.toml", "requirements.txt"} {
+		if _, err := os.Stat(file); err == nil {
+			installFroms = append(installFroms, file)
+		}
+	}
+
+	var tool = "venv"
+	if m.managerInUse.String() == "uv" {
+		tool = "uv"
+	}
+
+	return venvWizard{pathInput: pathInput, pythonInput: pythonInput, tool: tool, seedPip: true, installFroms: installFroms}
+}
+
+func createVirtualEnvAsync(m *model) tea.Cmd {
+	var opts = venvOptions{
+		path:        strings.TrimSpace(m.venvWizard.pathInput.Value()),
+		tool:        m.venvWizard.tool,
+		python:      strings.TrimSpace(m.venvWizard.pythonInput.Value()),
+		seedPip:     m.venvWizard.seedPip,
+		installFrom: m.venvWizard.installFrom,
+	}
+	var envs = m.environments
+	m.venvWizard.creating = true
+	m.info = fmt.Sprintf("%v Creating %v...", m.spinner.View(), opts.path)
+
+	return func() tea.Msg {
+		var env, res = createVirtualEnv(opts, envs)
+		return VenvCreatedMsg{env: env, res: res}
+	}
+}
+
+func focusWizardField(w *venvWizard, field int) {
+	w.field = (field + wizardFieldCount) % wizardFieldCount
+	w.pathInput.Blur()
+	w.pythonInput.Blur()
+	switch w.field {
+	case wizardFieldPath:
+		w.pathInput.Focus()
+	case wizardFieldPython:
+		w.pythonInput.Focus()
+	}
+}
+
+func cycleWizardChoice(w *venvWizard) {
+	switch w.field {
+	case wizardFieldTool:
+		if w.tool == "venv" {
+			w.tool = "uv"
+		} else {
+			w.tool = "venv"
+		}
+	case wizardFieldSeed:
+		w.seedPip = !w.seedPip
+	case wizardFieldInstall:
+		for i, from := range w.installFroms {
+			if from == w.installFrom {
+				w.installFrom = w.installFroms[(i+1)%len(w.installFroms)]
+				break
+			}
+		}
+	}
+}
+
+func updateVenvWizard(m *model, msg tea.KeyMsg) tea.Cmd {
+	var w = &m.venvWizard
+	if w.creating {
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.showVenvWizard = false
+		m.showVenvScreen = true
+		return nil
+	case "tab", "down":
+		focusWizardField(w, w.field+1)
+		return nil
+	case "shift+tab", "up":
+		focusWizardField(w, w.field-1)
+		return nil
+	case "enter":
+		if strings.TrimSpace(w.pathInput.Value()) == "" {
+			m.info = "The environment needs a path"
+			return nil
+		}
+		return createVirtualEnvAsync(m)
+	case "left", "right", " ":
+		if w.field != wizardFieldPath && w.field != wizardFieldPython {
+			cycleWizardChoice(w)
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch w.field {
+	case wizardFieldPath:
+		w.pathInput, cmd = w.pathInput.Update(msg)
+	case wizardFieldPython:
+		w.pythonInput, cmd = w.pythonInput.Update(msg)
+	}
+	return cmd
+}
+
+func drawVenvWizardScreen(m *model) string {
+	var w = m.venvWizard
+
+	var installFrom = w.installFrom
+	if installFrom == "" {
+		installFrom = "nothing"
+	}
+	var seed = "no"
+	if w.seedPip {
+		seed = "yes"
+	} else if w.tool == "venv" && w.installFrom != "" {
+		seed = "yes, venv installs with the environment's pip"
+	}
+
+	var fields = []string{
+		"Path:        " + w.pathInput.View(),
+		"Tool:        " + w.tool,
+		"Python:      " + w.pythonInput.View(),
+		"Seed pip:    " + seed,
+		"Install:     " + installFrom,
+	}
+
+	var lines []string
+	for i, field := range fields {
+		var style = lipgloss.NewStyle().Padding(0, 1)
+		if i == w.field {
+			style = style.Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+		}
+		lines = append(lines, style.Render(field))
+	}
+
+	var header = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(1, 0).
+		Render("New Virtual Environment")
+
+	var form = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("63")).
+		Padding(1, 2).
+		Width(m.window.width - 4).
+		Render(strings.Join(lines, "\n\n"))
+
+	var status = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("2")).
+		Padding(1, 0, 0, 0).
+		Render(m.info)
+
+	var footer = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(1, 0).
+		Render("Tab/↑↓: field • ←/→/Space: change • Enter: create • Esc: back")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		form,
+		status,
+		footer,
+	)
+}